@@ -17,13 +17,19 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	goflag "flag"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
 	sdcioCmd "github.com/sdcio/kubectl-sdcio/pkg/cmd"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/klog/v2"
 )
 
 func main() {
@@ -32,6 +38,15 @@ func main() {
 
 	root := &cobra.Command{
 		Use: "sdcio",
+		Long: `kubectl sdcio is the SDC specific kubectl plugin.
+
+Exit codes:
+  0    success
+  1    error
+  2    invalid flags or arguments
+  3    nothing matched, e.g. no blame node matches the filter
+  4    a check failed, e.g. the compared documents differ
+  130  interrupted by ctrl-c or SIGTERM`,
 		Annotations: map[string]string{
 			cobra.CommandDisplayNameAnnotation: "kubectl sdcio",
 		},
@@ -42,14 +57,99 @@ func main() {
 		panic(err)
 	}
 	root.AddCommand(blameCmd)
+
+	statusCmd, err := sdcioCmd.NewCmdStatus(genericiooptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	if err != nil {
+		panic(err)
+	}
+	root.AddCommand(statusCmd)
+
+	convertCmd, err := sdcioCmd.NewCmdConvert(genericiooptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	if err != nil {
+		panic(err)
+	}
+	root.AddCommand(convertCmd)
+
+	diffCmd, err := sdcioCmd.NewCmdDiff(genericiooptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	if err != nil {
+		panic(err)
+	}
+	root.AddCommand(diffCmd)
+
+	pushCmd, err := sdcioCmd.NewCmdPush(genericiooptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	if err != nil {
+		panic(err)
+	}
+	root.AddCommand(pushCmd)
+
+	getCmd, err := sdcioCmd.NewCmdGet(genericiooptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	if err != nil {
+		panic(err)
+	}
+	root.AddCommand(getCmd)
+
+	namespacesCmd, err := sdcioCmd.NewCmdNamespaces(genericiooptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	if err != nil {
+		panic(err)
+	}
+	root.AddCommand(namespacesCmd)
+
+	targetsCmd, err := sdcioCmd.NewCmdTargets(genericiooptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	if err != nil {
+		panic(err)
+	}
+	root.AddCommand(targetsCmd)
+
+	deviationsCmd, err := sdcioCmd.NewCmdDeviations(genericiooptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	if err != nil {
+		panic(err)
+	}
+	root.AddCommand(deviationsCmd)
 	root.AddCommand(completionCmd)
 	root.Version = "v0.0.0"
+
+	// klog writes to stderr, keeping stdout clean for the results
+	klogFlags := goflag.NewFlagSet("klog", goflag.ExitOnError)
+	klog.InitFlags(klogFlags)
+	verbose := pflag.PFlagFromGoFlag(klogFlags.Lookup("v"))
+	verbose.Name = "verbose"
+	verbose.Shorthand = "v"
+	verbose.Usage = "log verbosity to stderr: 1 logs retries, 2 API requests, 3 blame tree details"
+	root.PersistentFlags().AddFlag(verbose)
+	defer klog.Flush()
 	root.CompletionOptions.DisableDefaultCmd = false
+	root.SetFlagErrorFunc(sdcioCmd.FlagErrorFunc)
+
+	// errors are written by main, honoring --error-format
+	var errorFormat string
+	root.PersistentFlags().StringVar(&errorFormat, "error-format", sdcioCmd.ErrorFormatText, fmt.Sprintf("format of error messages written to stderr, one of %v", sdcioCmd.ErrorFormats))
+	root.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		// flags not given on the command line default to SDCIO_* env vars
+		// and the values of the config file
+		if err := sdcioCmd.PrepareFlags(cmd, sdcioCmd.DefaultConfigFile(), os.Getenv); err != nil {
+			return err
+		}
+		return sdcioCmd.ValidateErrorFormat(errorFormat)
+	}
+	root.SilenceErrors = true
+	if err := sdcioCmd.AddColorFlag(root); err != nil {
+		panic(err)
+	}
 
 	cobra.EnableCommandSorting = false
 
-	if err := root.Execute(); err != nil {
-		os.Exit(1)
+	// cancel the requests in flight on ctrl-c instead of waiting for them
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := root.ExecuteContext(ctx); err != nil {
+		if ctx.Err() != nil {
+			// the command fails with whatever error the cancellation caused,
+			// e.g. "context canceled", report the interruption instead
+			err = sdcioCmd.ErrInterrupted
+		}
+		sdcioCmd.WriteError(os.Stderr, err, errorFormat)
+		os.Exit(sdcioCmd.ExitCode(err))
 	}
 
 }
@@ -75,7 +175,7 @@ $ kubectl sdcio completion fish | source
 	DisableFlagsInUseLine: true,
 	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
 
-	Args: cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	Args: sdcioCmd.UsageArgs(cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs)),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		switch args[0] {
 		case "bash":
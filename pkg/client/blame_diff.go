@@ -0,0 +1,79 @@
+package client
+
+import (
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+)
+
+// BlameDiff is the structural difference between two blame trees.
+// Added and Removed only report the topmost node of a missing subtree.
+type BlameDiff struct {
+	// Added holds the paths only present in the second tree
+	Added []BlameDiffEntry `json:"added,omitempty"`
+	// Removed holds the paths only present in the first tree
+	Removed []BlameDiffEntry `json:"removed,omitempty"`
+	// Changed holds the paths present in both trees with a different value or owner
+	Changed []BlameDiffEntry `json:"changed,omitempty"`
+}
+
+type BlameDiffEntry struct {
+	Path   string `json:"path"`
+	OwnerA string `json:"ownerA,omitempty"`
+	OwnerB string `json:"ownerB,omitempty"`
+	ValueA string `json:"valueA,omitempty"`
+	ValueB string `json:"valueB,omitempty"`
+}
+
+// IsEmpty returns true if both trees were identical
+func (d *BlameDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffBlameTrees compares the blame trees of two devices. The root elements
+// themselves are not compared since they carry the device names.
+func DiffBlameTrees(a, b *sdcpb.BlameTreeElement) *BlameDiff {
+	diff := &BlameDiff{}
+	diffBlameChilds(a, b, "", diff)
+	return diff
+}
+
+func diffBlameChilds(a, b *sdcpb.BlameTreeElement, path string, diff *BlameDiff) {
+	bChilds := make(map[string]*sdcpb.BlameTreeElement, b.ChildCount())
+	for _, c := range b.GetChilds() {
+		bChilds[c.GetName()] = c
+	}
+
+	for ca := range a.SortedChildIterator() {
+		p := blamePath(path, ca.GetName())
+		cb, exists := bChilds[ca.GetName()]
+		if !exists {
+			diff.Removed = append(diff.Removed, BlameDiffEntry{Path: p, OwnerA: ca.GetOwner(), ValueA: blameValue(ca)})
+			continue
+		}
+		delete(bChilds, ca.GetName())
+		if ca.GetOwner() != cb.GetOwner() || blameValue(ca) != blameValue(cb) {
+			diff.Changed = append(diff.Changed, BlameDiffEntry{
+				Path:   p,
+				OwnerA: ca.GetOwner(),
+				OwnerB: cb.GetOwner(),
+				ValueA: blameValue(ca),
+				ValueB: blameValue(cb),
+			})
+		}
+		diffBlameChilds(ca, cb, p, diff)
+	}
+
+	for cb := range b.SortedChildIterator() {
+		if _, remaining := bChilds[cb.GetName()]; remaining {
+			diff.Added = append(diff.Added, BlameDiffEntry{Path: blamePath(path, cb.GetName()), OwnerB: cb.GetOwner(), ValueB: blameValue(cb)})
+		}
+	}
+}
+
+// blameValue returns the string representation of the value of the element,
+// or an empty string for non-leaf elements
+func blameValue(bte *sdcpb.BlameTreeElement) string {
+	if bte.GetValue() == nil {
+		return ""
+	}
+	return bte.GetValue().ToString()
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+)
+
+// newTestLeaf returns a leaf holding the string value, owned by owner
+func newTestLeaf(name string, owner string, value string) *sdcpb.BlameTreeElement {
+	return &sdcpb.BlameTreeElement{
+		Name:  name,
+		Owner: owner,
+		Value: &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: value}},
+	}
+}
+
+// newTestSystemTree returns a tree with the given leaves below /system
+func newTestSystemTree(device string, leaves ...*sdcpb.BlameTreeElement) *sdcpb.BlameTreeElement {
+	return &sdcpb.BlameTreeElement{
+		Name:   device,
+		Childs: []*sdcpb.BlameTreeElement{{Name: "system", Childs: leaves}},
+	}
+}
+
+func TestDiffBlameTrees(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *sdcpb.BlameTreeElement
+		want *BlameDiff
+	}{
+		{
+			name: "identical trees of different devices",
+			a:    newTestSystemTree("srl1", newTestLeaf("name", "default.intent", "x")),
+			b:    newTestSystemTree("srl2", newTestLeaf("name", "default.intent", "x")),
+			want: &BlameDiff{},
+		},
+		{
+			name: "added leaf",
+			a:    newTestSystemTree("srl1", newTestLeaf("name", "default.intent", "x")),
+			b:    newTestSystemTree("srl2", newTestLeaf("name", "default.intent", "x"), newTestLeaf("location", "running", "lab")),
+			want: &BlameDiff{Added: []BlameDiffEntry{{Path: "/system/location", OwnerB: "running", ValueB: "lab"}}},
+		},
+		{
+			name: "removed leaf",
+			a:    newTestSystemTree("srl1", newTestLeaf("name", "default.intent", "x"), newTestLeaf("location", "running", "lab")),
+			b:    newTestSystemTree("srl2", newTestLeaf("name", "default.intent", "x")),
+			want: &BlameDiff{Removed: []BlameDiffEntry{{Path: "/system/location", OwnerA: "running", ValueA: "lab"}}},
+		},
+		{
+			name: "changed owner",
+			a:    newTestSystemTree("srl1", newTestLeaf("name", "default.intent", "x")),
+			b:    newTestSystemTree("srl2", newTestLeaf("name", "default.other", "x")),
+			want: &BlameDiff{Changed: []BlameDiffEntry{{Path: "/system/name", OwnerA: "default.intent", OwnerB: "default.other", ValueA: "x", ValueB: "x"}}},
+		},
+		{
+			name: "changed value",
+			a:    newTestSystemTree("srl1", newTestLeaf("name", "default.intent", "x")),
+			b:    newTestSystemTree("srl2", newTestLeaf("name", "default.intent", "y")),
+			want: &BlameDiff{Changed: []BlameDiffEntry{{Path: "/system/name", OwnerA: "default.intent", OwnerB: "default.intent", ValueA: "x", ValueB: "y"}}},
+		},
+		{
+			name: "removed subtree reported once",
+			a: &sdcpb.BlameTreeElement{Name: "srl1", Childs: []*sdcpb.BlameTreeElement{
+				{Name: "interface", Childs: []*sdcpb.BlameTreeElement{
+					{Name: "ethernet-1/1", Childs: []*sdcpb.BlameTreeElement{newTestLeaf("mtu", "default.intent", "9000")}},
+				}},
+			}},
+			b:    &sdcpb.BlameTreeElement{Name: "srl2"},
+			want: &BlameDiff{Removed: []BlameDiffEntry{{Path: "/interface"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffBlameTrees(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffBlameTrees() = %+v, want %+v", got, tt.want)
+			}
+			if got.IsEmpty() != tt.want.IsEmpty() {
+				t.Errorf("IsEmpty() = %v, want %v", got.IsEmpty(), tt.want.IsEmpty())
+			}
+		})
+	}
+}
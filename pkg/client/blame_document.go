@@ -0,0 +1,87 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/sdcio/kubectl-sdcio/pkg/utils"
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+)
+
+// BlameDocumentAt returns the document of the node at the given path, nested
+// below the name of the node. For a list entry, the list name and the key
+// values are kept, e.g. /interface[name=ethernet-1/1] returns
+// {"interface": {"ethernet-1/1": {...}}}. An empty path or / returns the
+// document of bte.
+func BlameDocumentAt(bte *sdcpb.BlameTreeElement, path string) (map[string]any, error) {
+	segments := utils.SplitPath(path)
+	if len(segments) == 0 {
+		return BlameDocument(bte), nil
+	}
+	chain, resolved, err := findBlameChain(bte, path)
+	if err != nil {
+		return nil, err
+	}
+	if chain == nil {
+		return nil, fmt.Errorf("path %q not found, %q does not exist", path, resolved)
+	}
+
+	// the last element of the path resolved to the list or container node
+	// followed by one node per key value
+	sp, err := sdcpb.ParsePath("/" + segments[len(segments)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	elems := sp.GetElem()
+	nodes := len(elems[len(elems)-1].GetKey()) + 1
+
+	node := chain[len(chain)-1]
+	var v any
+	if node.GetValue() != nil {
+		v = BlameDocumentValue(node.GetValue())
+	} else {
+		v = BlameDocument(node)
+	}
+	for i := len(chain) - 1; i >= len(chain)-nodes; i-- {
+		v = map[string]any{chain[i].GetName(): v}
+	}
+	return v.(map[string]any), nil
+}
+
+// BlameDocument converts the children of bte into nested maps keyed by the
+// element names, leaves holding their values. The schema is not known, so
+// list entries remain nested below the list by their key values.
+func BlameDocument(bte *sdcpb.BlameTreeElement) map[string]any {
+	doc := make(map[string]any, bte.ChildCount())
+	for _, c := range bte.GetChilds() {
+		if c.GetValue() != nil {
+			doc[c.GetName()] = BlameDocumentValue(c.GetValue())
+			continue
+		}
+		doc[c.GetName()] = BlameDocument(c)
+	}
+	return doc
+}
+
+// BlameDocumentValue returns the value of tv as a plain go value, falling
+// back to its string representation for types without json equivalent
+func BlameDocumentValue(tv *sdcpb.TypedValue) any {
+	switch v := tv.GetValue().(type) {
+	case *sdcpb.TypedValue_IntVal:
+		return v.IntVal
+	case *sdcpb.TypedValue_UintVal:
+		return v.UintVal
+	case *sdcpb.TypedValue_BoolVal:
+		return v.BoolVal
+	case *sdcpb.TypedValue_DoubleVal:
+		return v.DoubleVal
+	case *sdcpb.TypedValue_FloatVal:
+		return v.FloatVal
+	case *sdcpb.TypedValue_LeaflistVal:
+		elems := make([]any, 0, len(v.LeaflistVal.GetElement()))
+		for _, e := range v.LeaflistVal.GetElement() {
+			elems = append(elems, BlameDocumentValue(e))
+		}
+		return elems
+	}
+	return tv.ToString()
+}
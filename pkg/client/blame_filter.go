@@ -0,0 +1,382 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+)
+
+// MatchMode defines how the criteria of a BlameFilter are combined
+type MatchMode string
+
+const (
+	// MatchAll requires a node to satisfy all criteria
+	MatchAll MatchMode = "all"
+	// MatchAny requires a node to satisfy at least one criterion
+	MatchAny MatchMode = "any"
+)
+
+// BlameFilter defines the criteria used to reduce a blame tree
+type BlameFilter struct {
+	// MatchMode defines how the criteria are combined, defaults to MatchAll
+	MatchMode MatchMode
+	// Path scopes the filter to the subtree at the xpath, list entries
+	// being addressed by their keys, e.g. /interface[name=ethernet-1/1].
+	// Empty or "/" means the whole tree.
+	Path string
+	// MaxDepth limits how deep the tree is walked, counted from the root.
+	// The children of nodes at MaxDepth are left out, GetFilteredBlameTree
+	// reports how many in its TruncatedNodes. 0 means unlimited.
+	MaxDepth int
+	// Deviation only keeps the nodes that deviate from the intended value
+	Deviation bool
+	// Priority only keeps the nodes owned by a Config of the given priority.
+	// The blame tree carries no priority, it is looked up from the Config
+	// resources owning the nodes.
+	Priority *int64
+	// ValueType only keeps the leaves with a value of the given type, one of ValueTypes
+	ValueType string
+	// Unmanaged only keeps the leaves no intent claims, i.e. without owner
+	Unmanaged bool
+	// Owner only keeps the nodes with the given owner, e.g. default.intent1
+	Owner string
+	// PathGlob only keeps the nodes at or below a blame path matching the
+	// glob, e.g. /interface/ethernet-1/* or /network-instance/*/protocols.
+	// A * matches any characters including /, as key values may hold
+	// slashes themselves.
+	PathGlob string
+
+	// ownerPriority maps blame owners to the priority of their Config
+	ownerPriority map[string]int64
+	// pathGlob is the compiled PathGlob, set by prepareFilter
+	pathGlob *regexp.Regexp
+}
+
+// HasCriteria returns true if the filter selects nodes, as opposed to only
+// limiting the depth of the tree
+func (f BlameFilter) HasCriteria() bool {
+	return f.Deviation || f.Priority != nil || f.ValueType != "" || f.Unmanaged ||
+		f.Owner != "" || f.PathGlob != ""
+}
+
+// Validate checks the filter settings
+func (f BlameFilter) Validate() error {
+	switch f.MatchMode {
+	case "", MatchAll, MatchAny:
+	default:
+		return fmt.Errorf("unknown match mode %q, must be one of [%s %s]", f.MatchMode, MatchAll, MatchAny)
+	}
+	if _, err := sdcpb.ParsePath(f.Path); err != nil {
+		return fmt.Errorf("invalid path %q: %w", f.Path, err)
+	}
+	if f.MaxDepth < 0 {
+		return fmt.Errorf("max depth must not be negative")
+	}
+	if f.ValueType != "" && !slices.Contains(ValueTypes, f.ValueType) {
+		return fmt.Errorf("unknown value type %q, must be one of %v", f.ValueType, ValueTypes)
+	}
+	if f.PathGlob != "" && !strings.HasPrefix(f.PathGlob, "/") {
+		return fmt.Errorf("invalid path glob %q, must start with /", f.PathGlob)
+	}
+	return nil
+}
+
+// pathGlobRegexp compiles the glob of BlameFilter.PathGlob into a regexp
+// matching the paths at or below the matching paths
+func pathGlobRegexp(glob string) *regexp.Regexp {
+	parts := strings.Split(glob, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile(`^` + strings.TrimSuffix(strings.Join(parts, ".*"), "/") + `(/.*)?$`)
+}
+
+// TruncatedNodes maps the nodes of a filtered blame tree whose children were
+// left out at the MaxDepth of the filter to the number of hidden children.
+// It is kept next to the tree, so the tree only holds nodes of the device.
+type TruncatedNodes map[*sdcpb.BlameTreeElement]int
+
+// GetFilteredBlameTree returns a copy of the device's blame tree reduced
+// according to filter, along with the nodes cut off at its MaxDepth
+func (c *ConfigClient) GetFilteredBlameTree(ctx context.Context, namespace string, device string, filter BlameFilter) (*sdcpb.BlameTreeElement, TruncatedNodes, error) {
+	if err := c.prepareFilter(ctx, namespace, &filter); err != nil {
+		return nil, nil, err
+	}
+	bte, err := c.GetBlameTree(ctx, namespace, device)
+	if err != nil {
+		return nil, nil, err
+	}
+	chain, err := scopeBlameTree(bte, filter.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// depth is counted from the root, also when scoped to a subtree
+	depth := len(chain) - 1
+	truncated := TruncatedNodes{}
+	result := filterBlameTree(chain[depth], chainPath(chain), filter, depth, truncated)
+	if result == nil {
+		// nothing matched, still return the root
+		return &sdcpb.BlameTreeElement{Name: bte.GetName(), Owner: bte.GetOwner()}, truncated, nil
+	}
+	// keep the ancestors of the subtree, without their other children
+	for i := depth - 1; i >= 0; i-- {
+		result = &sdcpb.BlameTreeElement{
+			Name:   chain[i].GetName(),
+			Owner:  chain[i].GetOwner(),
+			Childs: []*sdcpb.BlameTreeElement{result},
+		}
+	}
+	return result, truncated, nil
+}
+
+// scopeBlameTree returns the elements from the root of bte down to the
+// subtree at path, only the root if path is empty or "/"
+func scopeBlameTree(bte *sdcpb.BlameTreeElement, path string) ([]*sdcpb.BlameTreeElement, error) {
+	chain, resolved, err := findBlameChain(bte, path)
+	if err != nil {
+		return nil, err
+	}
+	if chain == nil {
+		return nil, fmt.Errorf("path %q not found, %q does not exist", path, resolved)
+	}
+	return chain, nil
+}
+
+// chainPath returns the blame path of the last element of chain
+func chainPath(chain []*sdcpb.BlameTreeElement) string {
+	path := ""
+	for _, e := range chain[1:] {
+		path = blamePath(path, e.GetName())
+	}
+	return path
+}
+
+// BlameVisitor is called for every node matching a BlameFilter, with the
+// path of the node relative to the root of the tree. Returning an error
+// stops the walk.
+type BlameVisitor func(path string, bte *sdcpb.BlameTreeElement) error
+
+// WalkFilteredBlameTree calls fn for every node of the device's blame tree
+// that matches the filter. Unlike GetFilteredBlameTree, no filtered copy of
+// the tree is built, matches are handed to fn as they are found. The blame
+// tree itself is still decoded at once, since the config-server returns it
+// as a single protojson document.
+func (c *ConfigClient) WalkFilteredBlameTree(ctx context.Context, namespace string, device string, filter BlameFilter, fn BlameVisitor) error {
+	if err := c.prepareFilter(ctx, namespace, &filter); err != nil {
+		return err
+	}
+	bte, err := c.GetBlameTree(ctx, namespace, device)
+	if err != nil {
+		return err
+	}
+	chain, err := scopeBlameTree(bte, filter.Path)
+	if err != nil {
+		return err
+	}
+	depth := len(chain) - 1
+	if depth == 0 {
+		return walkFilteredBlameTree(bte, "", filter, 1, fn)
+	}
+
+	// the subtree at the path is visited itself, unlike the root of the tree
+	path := chainPath(chain)
+	if filter.MaxDepth > 0 && depth > filter.MaxDepth {
+		return nil
+	}
+	if matchesFilter(chain[depth], path, filter) {
+		if err := fn(path, chain[depth]); err != nil {
+			return err
+		}
+	}
+	return walkFilteredBlameTree(chain[depth], path, filter, depth+1, fn)
+}
+
+func walkFilteredBlameTree(bte *sdcpb.BlameTreeElement, path string, filter BlameFilter, depth int, fn BlameVisitor) error {
+	if filter.MaxDepth > 0 && depth > filter.MaxDepth {
+		return nil
+	}
+	for c := range bte.SortedChildIterator() {
+		p := blamePath(path, c.GetName())
+		if matchesFilter(c, p, filter) {
+			if err := fn(p, c); err != nil {
+				return err
+			}
+		}
+		if err := walkFilteredBlameTree(c, p, filter, depth+1, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepareFilter validates the filter and looks up the information the
+// filter criteria depend on
+func (c *ConfigClient) prepareFilter(ctx context.Context, namespace string, filter *BlameFilter) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	if filter.PathGlob != "" {
+		filter.pathGlob = pathGlobRegexp(filter.PathGlob)
+	}
+	if filter.Priority != nil {
+		var err error
+		filter.ownerPriority, err = c.getOwnerPriorities(ctx, namespace)
+		if err != nil {
+			return fmt.Errorf("priority information not available: %w", err)
+		}
+	}
+	return nil
+}
+
+// getOwnerPriorities returns the priority of the Configs in the namespace,
+// keyed by the owner name they appear with in blame trees
+func (c *ConfigClient) getOwnerPriorities(ctx context.Context, namespace string) (map[string]int64, error) {
+	configs, err := c.ListConfigInfos(ctx, namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]int64, len(configs))
+	for _, cfg := range configs {
+		result[configOwner(cfg.Namespace, cfg.Name)] = cfg.Priority
+	}
+	return result, nil
+}
+
+// configOwner returns the owner name under which the config-server
+// records the nodes of a Config in the blame tree
+func configOwner(namespace string, name string) string {
+	return fmt.Sprintf("%s.%s", namespace, name)
+}
+
+// filterBlameTree returns a copy of bte reduced according to filter, or nil
+// if neither bte nor any of its descendants match the filter.
+// path and depth are the blame path and depth of bte relative to the root
+// of the tree. The copies of the nodes cut off at MaxDepth are added to
+// truncated.
+func filterBlameTree(bte *sdcpb.BlameTreeElement, path string, filter BlameFilter, depth int, truncated TruncatedNodes) *sdcpb.BlameTreeElement {
+	result := &sdcpb.BlameTreeElement{
+		Name:           bte.GetName(),
+		Owner:          bte.GetOwner(),
+		Value:          bte.GetValue(),
+		DeviationValue: bte.GetDeviationValue(),
+	}
+
+	if filter.MaxDepth > 0 && depth >= filter.MaxDepth {
+		if !subtreeMatches(bte, path, filter) {
+			return nil
+		}
+		hidden := 0
+		for _, child := range bte.GetChilds() {
+			if subtreeMatches(child, blamePath(path, child.GetName()), filter) {
+				hidden++
+			}
+		}
+		if hidden > 0 {
+			truncated[result] = hidden
+		}
+		return result
+	}
+
+	for _, child := range bte.GetChilds() {
+		if c := filterBlameTree(child, blamePath(path, child.GetName()), filter, depth+1, truncated); c != nil {
+			result.Childs = append(result.Childs, c)
+		}
+	}
+
+	if len(result.Childs) > 0 || matchesFilter(bte, path, filter) {
+		return result
+	}
+	return nil
+}
+
+// matchesFilter returns true if the element at path itself satisfies the
+// filter criteria, combined according to the filter's MatchMode
+func matchesFilter(bte *sdcpb.BlameTreeElement, path string, filter BlameFilter) bool {
+	if !filter.HasCriteria() {
+		return true
+	}
+
+	results := []bool{}
+	if filter.Deviation {
+		results = append(results, bte.IsDeviated())
+	}
+	if filter.Priority != nil {
+		priority, exists := filter.ownerPriority[bte.GetOwner()]
+		results = append(results, exists && priority == *filter.Priority)
+	}
+	if filter.ValueType != "" {
+		results = append(results, matchesValueType(bte.GetValue(), filter.ValueType))
+	}
+	if filter.Unmanaged {
+		results = append(results, bte.GetValue() != nil && bte.GetOwner() == "")
+	}
+	if filter.Owner != "" {
+		results = append(results, bte.GetOwner() == filter.Owner)
+	}
+	if filter.PathGlob != "" {
+		results = append(results, filter.pathGlob != nil && filter.pathGlob.MatchString(path))
+	}
+
+	if filter.MatchMode == MatchAny {
+		return slices.Contains(results, true)
+	}
+	return !slices.Contains(results, false)
+}
+
+// subtreeMatches returns true if bte at path or any of its descendants match
+// the filter
+func subtreeMatches(bte *sdcpb.BlameTreeElement, path string, filter BlameFilter) bool {
+	if matchesFilter(bte, path, filter) {
+		return true
+	}
+	for _, c := range bte.GetChilds() {
+		if subtreeMatches(c, blamePath(path, c.GetName()), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// blamePath returns the path of the child called name below parentPath
+func blamePath(parentPath string, name string) string {
+	return parentPath + "/" + name
+}
+
+// walkBlameTree calls fn for every descendant of bte with its path relative to bte
+func walkBlameTree(bte *sdcpb.BlameTreeElement, path string, fn func(path string, bte *sdcpb.BlameTreeElement)) {
+	for c := range bte.SortedChildIterator() {
+		p := blamePath(path, c.GetName())
+		fn(p, c)
+		walkBlameTree(c, p, fn)
+	}
+}
+
+// BlameLeaf is a single leaf of a flattened blame tree
+type BlameLeaf struct {
+	Path           string `json:"path"`
+	Value          string `json:"value"`
+	Owner          string `json:"owner"`
+	Type           string `json:"type"`
+	DeviationValue string `json:"deviationValue,omitempty"`
+}
+
+// FlattenBlameTree returns the leaves of the tree, sorted by path
+func FlattenBlameTree(bte *sdcpb.BlameTreeElement) []BlameLeaf {
+	result := []BlameLeaf{}
+	walkBlameTree(bte, "", func(path string, e *sdcpb.BlameTreeElement) {
+		if e.GetValue() == nil {
+			return
+		}
+		leaf := BlameLeaf{Path: path, Value: blameValue(e), Owner: e.GetOwner(), Type: ValueType(e.GetValue())}
+		if e.IsDeviated() {
+			leaf.DeviationValue = e.GetDeviationValue().ToString()
+		}
+		result = append(result, leaf)
+	})
+	return result
+}
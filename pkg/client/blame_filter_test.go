@@ -0,0 +1,324 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	configv1alpha1 "github.com/sdcio/config-server/apis/config/v1alpha1"
+	"github.com/sdcio/config-server/pkg/generated/clientset/versioned/fake"
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestFilterBlameTreeMaxDepth(t *testing.T) {
+	bte := newTestBlameTree(
+		[]string{"interface", "ethernet-1/1", "mtu"},
+		[]string{"interface", "ethernet-1/1", "description"},
+		[]string{"interface", "ethernet-1/2", "mtu"},
+		[]string{"system", "name"},
+	)
+
+	truncated := TruncatedNodes{}
+	result := filterBlameTree(bte, "", BlameFilter{MaxDepth: 2}, 0, truncated)
+	want := map[string][]string{
+		"/interface":              {"ethernet-1/1", "ethernet-1/2"},
+		"/interface/ethernet-1/1": {},
+		"/interface/ethernet-1/2": {},
+		"/system":                 {"name"},
+	}
+	wantTruncated := map[string]int{
+		"/interface/ethernet-1/1": 2,
+		"/interface/ethernet-1/2": 1,
+	}
+	gotTruncated := map[string]int{}
+	walkBlameTree(result, "", func(path string, e *sdcpb.BlameTreeElement) {
+		if hidden, exists := truncated[e]; exists {
+			gotTruncated[path] = hidden
+		}
+		children, checked := want[path]
+		if !checked {
+			return
+		}
+		got := []string{}
+		for _, c := range e.GetChilds() {
+			got = append(got, c.GetName())
+		}
+		if !slices.Equal(got, children) {
+			t.Errorf("children of %s = %q, want %q", path, got, children)
+		}
+	})
+	if !maps.Equal(gotTruncated, wantTruncated) {
+		t.Errorf("truncated %v, want %v", gotTruncated, wantTruncated)
+	}
+
+	if counts := CountBlameNodes(result); counts.Total != 5 {
+		t.Errorf("got %d nodes, want 5", counts.Total)
+	}
+	if leaves := FlattenBlameTree(result); len(leaves) != 1 || leaves[0].Path != "/system/name" {
+		t.Errorf("flattened to %v, want /system/name only", leaves)
+	}
+}
+
+// newTestBlameClient returns a client serving bte as the blame tree of the
+// target srl1 in the default namespace
+func newTestBlameClient(t *testing.T, bte *sdcpb.BlameTreeElement) *ConfigClient {
+	t.Helper()
+	raw, err := protojson.Marshal(bte)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ConfigClient{c: fake.NewSimpleClientset(&configv1alpha1.ConfigBlame{
+		ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: "srl1"},
+		Status:     configv1alpha1.ConfigBlameStatus{Value: runtime.RawExtension{Raw: raw}},
+	})}
+}
+
+func TestGetFilteredBlameTreePath(t *testing.T) {
+	bte := newTestBlameTree(
+		[]string{"interface", "ethernet-1/1", "mtu"},
+		[]string{"interface", "ethernet-1/1", "description"},
+		[]string{"interface", "ethernet-1/2", "mtu"},
+		[]string{"system", "name"},
+	)
+	// a leaf no intent claims
+	description, _, _ := findBlameNode(bte, "/interface[name=ethernet-1/1]/description")
+	description.Owner = ""
+	c := newTestBlameClient(t, bte)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		filter BlameFilter
+		want   []string
+	}{
+		{
+			name:   "whole tree",
+			filter: BlameFilter{Path: "/"},
+			want:   []string{"/interface/ethernet-1/1/description", "/interface/ethernet-1/1/mtu", "/interface/ethernet-1/2/mtu", "/system/name"},
+		},
+		{
+			name:   "list entry",
+			filter: BlameFilter{Path: "/interface[name=ethernet-1/1]"},
+			want:   []string{"/interface/ethernet-1/1/description", "/interface/ethernet-1/1/mtu"},
+		},
+		{
+			name:   "leaf",
+			filter: BlameFilter{Path: "/system/name"},
+			want:   []string{"/system/name"},
+		},
+		{
+			name:   "composed with unmanaged",
+			filter: BlameFilter{Path: "/interface", Unmanaged: true},
+			want:   []string{"/interface/ethernet-1/1/description"},
+		},
+		{
+			name:   "composed with unmanaged without match",
+			filter: BlameFilter{Path: "/system", Unmanaged: true},
+			want:   []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := c.GetFilteredBlameTree(ctx, "default", "srl1", tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.GetName() != "root" {
+				t.Errorf("result rooted at %q, want root", result.GetName())
+			}
+			got := []string{}
+			for _, l := range FlattenBlameTree(result) {
+				got = append(got, l.Path)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got leaves %q, want %q", got, tt.want)
+			}
+
+			walked := []string{}
+			err = c.WalkFilteredBlameTree(ctx, "default", "srl1", tt.filter, func(path string, e *sdcpb.BlameTreeElement) error {
+				if e.GetValue() != nil {
+					walked = append(walked, path)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(walked, tt.want) {
+				t.Errorf("walked leaves %q, want %q", walked, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetFilteredBlameTreeInvalidPath(t *testing.T) {
+	c := newTestBlameClient(t, newTestBlameTree([]string{"system", "name"}))
+	for _, path := range []string{"/interface[name=ethernet-1/1]", "/system[name"} {
+		if _, _, err := c.GetFilteredBlameTree(context.Background(), "default", "srl1", BlameFilter{Path: path}); err == nil {
+			t.Errorf("expected an error for path %q", path)
+		}
+	}
+}
+
+func TestGetFilteredBlameTreeOwnerAndPathGlob(t *testing.T) {
+	bte := newTestBlameTree(
+		[]string{"interface", "ethernet-1/1", "mtu"},
+		[]string{"interface", "ethernet-1/10", "mtu"},
+		[]string{"interface", "ethernet-1/2", "mtu"},
+		[]string{"system", "name"},
+		[]string{"system", "location"},
+	)
+	for _, p := range []string{"/interface[name=ethernet-1/2]/mtu", "/system/location"} {
+		node, _, _ := findBlameNode(bte, p)
+		node.Owner = "default.other"
+	}
+	c := newTestBlameClient(t, bte)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		filter BlameFilter
+		want   []string
+	}{
+		{
+			name:   "owner",
+			filter: BlameFilter{Owner: "default.other"},
+			want:   []string{"/interface/ethernet-1/2/mtu", "/system/location"},
+		},
+		{
+			name:   "path glob of a list entry",
+			filter: BlameFilter{PathGlob: "/interface/ethernet-1/1"},
+			want:   []string{"/interface/ethernet-1/1/mtu"},
+		},
+		{
+			name:   "path glob with wildcard",
+			filter: BlameFilter{PathGlob: "/interface/ethernet-1/1*"},
+			want:   []string{"/interface/ethernet-1/1/mtu", "/interface/ethernet-1/10/mtu"},
+		},
+		{
+			name:   "path glob with wildcard in the middle",
+			filter: BlameFilter{PathGlob: "/interface/*/mtu"},
+			want:   []string{"/interface/ethernet-1/1/mtu", "/interface/ethernet-1/10/mtu", "/interface/ethernet-1/2/mtu"},
+		},
+		{
+			name:   "owned by other or under system",
+			filter: BlameFilter{MatchMode: MatchAny, Owner: "default.other", PathGlob: "/system"},
+			want:   []string{"/interface/ethernet-1/2/mtu", "/system/location", "/system/name"},
+		},
+		{
+			name:   "owned by other and under system",
+			filter: BlameFilter{MatchMode: MatchAll, Owner: "default.other", PathGlob: "/system"},
+			want:   []string{"/system/location"},
+		},
+		{
+			name:   "no match",
+			filter: BlameFilter{PathGlob: "/network-instance"},
+			want:   []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := c.GetFilteredBlameTree(ctx, "default", "srl1", tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, l := range FlattenBlameTree(result) {
+				got = append(got, l.Path)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got leaves %q, want %q", got, tt.want)
+			}
+
+			walked := []string{}
+			err = c.WalkFilteredBlameTree(ctx, "default", "srl1", tt.filter, func(path string, e *sdcpb.BlameTreeElement) error {
+				if e.GetValue() != nil {
+					walked = append(walked, path)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(walked, tt.want) {
+				t.Errorf("walked leaves %q, want %q", walked, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlameFilterInvalidPathGlob(t *testing.T) {
+	if err := (BlameFilter{PathGlob: "interface/*"}).Validate(); err == nil {
+		t.Error("expected an error for a relative path glob")
+	}
+}
+
+// newBenchmarkBlameTree returns a tree of interfaces with subinterfaces,
+// holding leaves leaves of which every deviateEvery-th one deviates
+func newBenchmarkBlameTree(interfaces, subinterfaces, leaves, deviateEvery int) *sdcpb.BlameTreeElement {
+	root := &sdcpb.BlameTreeElement{Name: "root"}
+	list := &sdcpb.BlameTreeElement{Name: "interface"}
+	root.Childs = append(root.Childs, list)
+	n := 0
+	for i := range interfaces {
+		itf := &sdcpb.BlameTreeElement{Name: fmt.Sprintf("ethernet-1/%d", i)}
+		list.Childs = append(list.Childs, itf)
+		for s := range subinterfaces {
+			sub := &sdcpb.BlameTreeElement{Name: fmt.Sprintf("%d", s)}
+			itf.Childs = append(itf.Childs, &sdcpb.BlameTreeElement{Name: "subinterface", Childs: []*sdcpb.BlameTreeElement{sub}})
+			for l := range leaves {
+				leaf := &sdcpb.BlameTreeElement{
+					Name:  fmt.Sprintf("leaf-%d", l),
+					Owner: "default.intent",
+					Value: &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "intended"}},
+				}
+				if n%deviateEvery == 0 {
+					leaf.DeviationValue = &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "running"}}
+				}
+				sub.Childs = append(sub.Childs, leaf)
+				n++
+			}
+		}
+	}
+	return root
+}
+
+// BenchmarkDeviations compares collecting the deviations of a large tree
+// from a filtered copy, as GetFilteredBlameTree does, with walking the tree
+// with the filter, as WalkFilteredBlameTree does
+func BenchmarkDeviations(b *testing.B) {
+	filter := BlameFilter{Deviation: true}
+	for _, deviateEvery := range []int{100, 2} {
+		bte := newBenchmarkBlameTree(200, 50, 10, deviateEvery)
+
+		b.Run(fmt.Sprintf("filtered-copy/1-in-%d", deviateEvery), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				paths := []string{}
+				walkBlameTree(filterBlameTree(bte, "", filter, 0, TruncatedNodes{}), "", func(path string, e *sdcpb.BlameTreeElement) {
+					if e.IsDeviated() {
+						paths = append(paths, path)
+					}
+				})
+			}
+		})
+		b.Run(fmt.Sprintf("walk/1-in-%d", deviateEvery), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				paths := []string{}
+				err := walkFilteredBlameTree(bte, "", filter, 1, func(path string, _ *sdcpb.BlameTreeElement) error {
+					paths = append(paths, path)
+					return nil
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"sort"
+
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+)
+
+// OwnerStats holds the number of leaves owned by a single owner.
+// An empty Owner stands for unmanaged leaves.
+type OwnerStats struct {
+	Owner    string `json:"owner"`
+	Leaves   int    `json:"leaves"`
+	Deviated int    `json:"deviated"`
+}
+
+// BlameStatsByOwner counts the leaves and deviated nodes of the tree per owner.
+// The result is sorted by descending leaf count.
+func BlameStatsByOwner(bte *sdcpb.BlameTreeElement) []OwnerStats {
+	stats := map[string]*OwnerStats{}
+	collectOwnerStats(bte, stats)
+
+	result := make([]OwnerStats, 0, len(stats))
+	for _, s := range stats {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Leaves != result[j].Leaves {
+			return result[i].Leaves > result[j].Leaves
+		}
+		return result[i].Owner < result[j].Owner
+	})
+	return result
+}
+
+func collectOwnerStats(bte *sdcpb.BlameTreeElement, stats map[string]*OwnerStats) {
+	isLeaf := bte.GetValue() != nil
+	if isLeaf || bte.IsDeviated() {
+		s, exists := stats[bte.GetOwner()]
+		if !exists {
+			s = &OwnerStats{Owner: bte.GetOwner()}
+			stats[bte.GetOwner()] = s
+		}
+		if isLeaf {
+			s.Leaves++
+		}
+		if bte.IsDeviated() {
+			s.Deviated++
+		}
+	}
+	for _, c := range bte.GetChilds() {
+		collectOwnerStats(c, stats)
+	}
+}
+
+// BlameCounts holds the number of nodes of a blame tree
+type BlameCounts struct {
+	Total    int `json:"total"`
+	Deviated int `json:"deviated"`
+}
+
+// DeviatedPercent returns the share of deviated nodes in percent
+func (bc BlameCounts) DeviatedPercent() float64 {
+	if bc.Total == 0 {
+		return 0
+	}
+	return float64(bc.Deviated) * 100 / float64(bc.Total)
+}
+
+// CountBlameNodes counts the nodes below the root of the tree and how many of
+// them are deviated
+func CountBlameNodes(bte *sdcpb.BlameTreeElement) BlameCounts {
+	counts := BlameCounts{}
+	walkBlameTree(bte, "", func(_ string, e *sdcpb.BlameTreeElement) {
+		counts.Total++
+		if e.IsDeviated() {
+			counts.Deviated++
+		}
+	})
+	return counts
+}
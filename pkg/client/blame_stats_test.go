@@ -0,0 +1,61 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+)
+
+// newTestStatsTree returns a tree with leaves of two intents, the device
+// and no owner, two of them deviated
+func newTestStatsTree() *sdcpb.BlameTreeElement {
+	deviated := func(leaf *sdcpb.BlameTreeElement) *sdcpb.BlameTreeElement {
+		leaf.DeviationValue = &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "y"}}
+		return leaf
+	}
+	return &sdcpb.BlameTreeElement{
+		Name: "srl1",
+		Childs: []*sdcpb.BlameTreeElement{
+			{Name: "system", Childs: []*sdcpb.BlameTreeElement{
+				newTestLeaf("name", "default.intent1", "srl1"),
+				deviated(newTestLeaf("contact", "default.intent1", "noc")),
+				newTestLeaf("location", "default.intent2", "lab"),
+				newTestLeaf("uptime", "running", "10"),
+			}},
+			{Name: "interface", Childs: []*sdcpb.BlameTreeElement{
+				{Name: "ethernet-1/1", Childs: []*sdcpb.BlameTreeElement{
+					newTestLeaf("mtu", "default.intent1", "9000"),
+					deviated(newTestLeaf("description", "", "uplink")),
+				}},
+			}},
+		},
+	}
+}
+
+func TestBlameStatsByOwner(t *testing.T) {
+	want := []OwnerStats{
+		{Owner: "default.intent1", Leaves: 3, Deviated: 1},
+		{Owner: "", Leaves: 1, Deviated: 1},
+		{Owner: "default.intent2", Leaves: 1},
+		{Owner: "running", Leaves: 1},
+	}
+	if got := BlameStatsByOwner(newTestStatsTree()); !reflect.DeepEqual(got, want) {
+		t.Errorf("BlameStatsByOwner() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestCountBlameNodes(t *testing.T) {
+	// system, 4 leaves, interface, ethernet-1/1 and 2 leaves
+	want := BlameCounts{Total: 9, Deviated: 2}
+	got := CountBlameNodes(newTestStatsTree())
+	if got != want {
+		t.Errorf("CountBlameNodes() = %+v, want %+v", got, want)
+	}
+	if p := got.DeviatedPercent(); p < 22.2 || p > 22.3 {
+		t.Errorf("DeviatedPercent() = %f, want 22.2", p)
+	}
+	if p := CountBlameNodes(&sdcpb.BlameTreeElement{Name: "srl1"}).DeviatedPercent(); p != 0 {
+		t.Errorf("DeviatedPercent() of an empty tree = %f, want 0", p)
+	}
+}
@@ -2,16 +2,29 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
 
+	configv1alpha1 "github.com/sdcio/config-server/apis/config/v1alpha1"
+	invv1alpha1 "github.com/sdcio/config-server/apis/inv/v1alpha1"
 	configCR "github.com/sdcio/config-server/pkg/generated/clientset/versioned"
 	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
 	"google.golang.org/protobuf/encoding/protojson"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 )
 
 type ConfigClient struct {
-	c *configCR.Clientset
+	c configCR.Interface
+	// retries is the number of times transient API errors are retried
+	retries int
 }
 
 func NewConfigClient(restConfig *rest.Config) (*ConfigClient, error) {
@@ -21,12 +34,23 @@ func NewConfigClient(restConfig *rest.Config) (*ConfigClient, error) {
 	}
 
 	return &ConfigClient{
-		c: clientset,
+		c:       clientset,
+		retries: DefaultRetries,
 	}, nil
 }
 
+// SetRetries sets the number of times transient API errors are retried
+func (c *ConfigClient) SetRetries(retries int) {
+	c.retries = max(retries, 0)
+}
+
 func (c *ConfigClient) GetBlameTree(ctx context.Context, namespace string, device string) (*sdcpb.BlameTreeElement, error) {
-	resp, err := c.c.ConfigV1alpha1().ConfigBlames(namespace).Get(ctx, device, v1.GetOptions{})
+	klog.V(2).InfoS("Fetching blame tree", "namespace", namespace, "target", device)
+	var resp *configv1alpha1.ConfigBlame
+	err := c.withRetry(ctx, func() (err error) {
+		resp, err = c.c.ConfigV1alpha1().ConfigBlames(namespace).Get(ctx, device, v1.GetOptions{})
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -36,11 +60,74 @@ func (c *ConfigClient) GetBlameTree(ctx context.Context, namespace string, devic
 	if err != nil {
 		return nil, err
 	}
+	klog.V(3).InfoS("Parsed blame tree", "namespace", namespace, "target", device, "bytes", len(resp.Status.Value.Raw))
 	return bte, nil
 }
 
+// TargetRef identifies a target across namespaces
+type TargetRef struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// NamespacedBlameTree is a blame tree together with the device it belongs to
+type NamespacedBlameTree struct {
+	Namespace string                  `json:"namespace"`
+	Device    string                  `json:"device"`
+	Tree      *sdcpb.BlameTreeElement `json:"tree"`
+}
+
+// ListBlameTrees returns the blame trees of all devices in the namespace.
+// An empty namespace (v1.NamespaceAll) lists the blame trees of all namespaces.
+func (c *ConfigClient) ListBlameTrees(ctx context.Context, namespace string) ([]NamespacedBlameTree, error) {
+	klog.V(2).InfoS("Listing blame trees", "namespace", namespace)
+	var resp *configv1alpha1.ConfigBlameList
+	err := c.withRetry(ctx, func() (err error) {
+		resp, err = c.c.ConfigV1alpha1().ConfigBlames(namespace).List(ctx, v1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]NamespacedBlameTree, 0, len(resp.Items))
+
+	for _, i := range resp.Items {
+		bte := &sdcpb.BlameTreeElement{}
+		err = protojson.Unmarshal([]byte(i.Status.Value.Raw), bte)
+		if err != nil {
+			return nil, fmt.Errorf("blame %s/%s: %w", i.Namespace, i.Name, err)
+		}
+		result = append(result, NamespacedBlameTree{Namespace: i.Namespace, Device: i.Name, Tree: bte})
+	}
+	return result, nil
+}
+
+// ListTargetRefs returns the targets in the namespace.
+// An empty namespace (v1.NamespaceAll) lists the targets of all namespaces.
+func (c *ConfigClient) ListTargetRefs(ctx context.Context, namespace string) ([]TargetRef, error) {
+	resp, err := c.listTargets(ctx, namespace, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TargetRef, 0, len(resp.Items))
+
+	for _, i := range resp.Items {
+		result = append(result, TargetRef{Namespace: i.Namespace, Name: i.Name})
+	}
+
+	return result, nil
+}
+
 func (c *ConfigClient) GetTargetNames(ctx context.Context, namespace string) ([]string, error) {
-	resp, err := c.c.InvV1alpha1().Targets(namespace).List(ctx, v1.ListOptions{})
+	return c.GetTargetNamesWithSelector(ctx, namespace, "")
+}
+
+// GetTargetNamesWithSelector returns the names of the targets in the
+// namespace matching the labelSelector
+func (c *ConfigClient) GetTargetNamesWithSelector(ctx context.Context, namespace string, labelSelector string) ([]string, error) {
+	resp, err := c.listTargets(ctx, namespace, labelSelector)
 	if err != nil {
 		return nil, err
 	}
@@ -53,3 +140,307 @@ func (c *ConfigClient) GetTargetNames(ctx context.Context, namespace string) ([]
 
 	return result, nil
 }
+
+// listTargets lists the targets in the namespace matching the labelSelector
+func (c *ConfigClient) listTargets(ctx context.Context, namespace string, labelSelector string) (*invv1alpha1.TargetList, error) {
+	if _, err := labels.Parse(labelSelector); err != nil {
+		return nil, fmt.Errorf("invalid label selector %q: %w", labelSelector, err)
+	}
+
+	klog.V(2).InfoS("Listing targets", "namespace", namespace, "selector", labelSelector)
+	var resp *invv1alpha1.TargetList
+	err := c.withRetry(ctx, func() (err error) {
+		resp, err = c.c.InvV1alpha1().Targets(namespace).List(ctx, v1.ListOptions{LabelSelector: labelSelector})
+		return err
+	})
+	return resp, err
+}
+
+// ConfigInfo summarizes the metadata of a Config resource
+type ConfigInfo struct {
+	Namespace string  `json:"namespace"`
+	Name      string  `json:"name"`
+	Target    string  `json:"target"`
+	Priority  int64   `json:"priority"`
+	Ready     bool    `json:"ready"`
+	Created   v1.Time `json:"created"`
+}
+
+func (c *ConfigClient) ListConfigs(ctx context.Context, namespace string) ([]string, error) {
+	infos, err := c.ListConfigInfos(ctx, namespace, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(infos))
+	for _, i := range infos {
+		result = append(result, i.Name)
+	}
+	return result, nil
+}
+
+// ListConfigInfos lists the Config resources in the namespace, optionally
+// restricted to those matching the labelSelector
+func (c *ConfigClient) ListConfigInfos(ctx context.Context, namespace string, labelSelector string) ([]ConfigInfo, error) {
+	klog.V(2).InfoS("Listing configs", "namespace", namespace, "selector", labelSelector)
+	var resp *configv1alpha1.ConfigList
+	err := c.withRetry(ctx, func() (err error) {
+		resp, err = c.c.ConfigV1alpha1().Configs(namespace).List(ctx, v1.ListOptions{LabelSelector: labelSelector})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ConfigInfo, 0, len(resp.Items))
+
+	for _, i := range resp.Items {
+		result = append(result, ConfigInfo{
+			Namespace: i.Namespace,
+			Name:      i.Name,
+			Target:    i.GetTarget(),
+			Priority:  i.Spec.Priority,
+			Ready:     i.IsConditionReady(),
+			Created:   i.CreationTimestamp,
+		})
+	}
+
+	return result, nil
+}
+
+// ConfigIntent is a decoded entry of the spec.config of a Config resource
+type ConfigIntent struct {
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// GetConfig returns the Config resource with the given name
+func (c *ConfigClient) GetConfig(ctx context.Context, namespace string, name string) (*configv1alpha1.Config, error) {
+	var cfg *configv1alpha1.Config
+	err := c.withRetry(ctx, func() (err error) {
+		cfg, err = c.c.ConfigV1alpha1().Configs(namespace).Get(ctx, name, v1.GetOptions{})
+		return err
+	})
+	return cfg, err
+}
+
+// FieldManager is the field manager used for server-side apply
+const FieldManager = "kubectl-sdcio"
+
+// ApplyConfig creates or updates the Config resource using server-side
+// apply, so applying the same Config again is a no-op
+func (c *ConfigClient) ApplyConfig(ctx context.Context, cfg *configv1alpha1.Config) (*configv1alpha1.Config, error) {
+	obj := &configv1alpha1.Config{
+		TypeMeta:   cfg.TypeMeta,
+		ObjectMeta: *cfg.ObjectMeta.DeepCopy(),
+		Spec:       *cfg.Spec.DeepCopy(),
+	}
+	if obj.APIVersion == "" {
+		obj.APIVersion = configv1alpha1.SchemeGroupVersion.Identifier()
+	}
+	if obj.Kind == "" {
+		obj.Kind = configv1alpha1.ConfigKind
+	}
+	// server-side apply rejects server populated metadata
+	obj.ResourceVersion = ""
+	obj.UID = ""
+	obj.ManagedFields = nil
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	// applying is idempotent, so a patch failing in flight can be retried
+	var applied *configv1alpha1.Config
+	err = c.withRetry(ctx, func() (err error) {
+		applied, err = c.c.ConfigV1alpha1().Configs(obj.Namespace).Patch(ctx, obj.Name, types.ApplyPatchType, data, v1.PatchOptions{
+			FieldManager: FieldManager,
+			Force:        ptr.To(true),
+		})
+		return err
+	})
+	return applied, err
+}
+
+// DeleteConfig deletes the Config resource with the given name. If it does
+// not exist, the returned error wraps the NotFound API error.
+func (c *ConfigClient) DeleteConfig(ctx context.Context, namespace string, name string) error {
+	attempts := 0
+	err := c.withRetry(ctx, func() error {
+		attempts++
+		return c.c.ConfigV1alpha1().Configs(namespace).Delete(ctx, name, v1.DeleteOptions{})
+	})
+	// a retried delete may find the Config deleted by the attempt that failed in flight
+	if apierrors.IsNotFound(err) && attempts > 1 {
+		return nil
+	}
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("config %s/%s not found: %w", namespace, name, err)
+	}
+	return err
+}
+
+// GetConfigIntent returns the decoded spec.config of the Config resource with the given name
+func (c *ConfigClient) GetConfigIntent(ctx context.Context, namespace string, name string) ([]ConfigIntent, error) {
+	cfg, err := c.GetConfig(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeConfigIntent(cfg)
+}
+
+// DecodeConfigIntent decodes the raw values of the spec.config of cfg
+func DecodeConfigIntent(cfg *configv1alpha1.Config) ([]ConfigIntent, error) {
+	result := make([]ConfigIntent, 0, len(cfg.Spec.Config))
+	for _, blob := range cfg.Spec.Config {
+		var value any
+		if len(blob.Value.Raw) > 0 {
+			if err := json.Unmarshal(blob.Value.Raw, &value); err != nil {
+				return nil, fmt.Errorf("config %s/%s path %q: %w", cfg.Namespace, cfg.Name, blob.Path, err)
+			}
+		}
+		result = append(result, ConfigIntent{Path: blob.Path, Value: value})
+	}
+	return result, nil
+}
+
+// ConfigEvent describes a change to a Config resource
+type ConfigEvent struct {
+	Type            watch.EventType
+	Namespace       string
+	Name            string
+	ResourceVersion string
+}
+
+// WatchConfigs streams the add/update/delete events of the Config resources in
+// the namespace. The returned channel is closed when ctx is cancelled or the
+// watch is terminated by the server.
+func (c *ConfigClient) WatchConfigs(ctx context.Context, namespace string) (<-chan ConfigEvent, error) {
+	var w watch.Interface
+	err := c.withRetry(ctx, func() (err error) {
+		w, err = c.c.ConfigV1alpha1().Configs(namespace).Watch(ctx, v1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan ConfigEvent)
+	go func() {
+		defer close(events)
+		defer w.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-w.ResultChan():
+				if !ok {
+					return
+				}
+				cfg, ok := e.Object.(*configv1alpha1.Config)
+				if !ok {
+					// bookmarks and error statuses carry no config
+					continue
+				}
+				select {
+				case events <- ConfigEvent{
+					Type:            e.Type,
+					Namespace:       cfg.Namespace,
+					Name:            cfg.Name,
+					ResourceVersion: cfg.ResourceVersion,
+				}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// TargetInfo summarizes the status of a Target resource
+type TargetInfo struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Provider  string `json:"provider"`
+	Address   string `json:"address"`
+	Connected bool   `json:"connected"`
+	Ready     bool   `json:"ready"`
+	// Reason explains why the target is not ready
+	Reason string `json:"reason,omitempty"`
+}
+
+// GetTargets returns the status of the targets in the namespace
+func (c *ConfigClient) GetTargets(ctx context.Context, namespace string) ([]TargetInfo, error) {
+	return c.GetTargetsWithSelector(ctx, namespace, "")
+}
+
+// GetTargetsWithSelector returns the status of the targets in the namespace
+// matching the labelSelector
+func (c *ConfigClient) GetTargetsWithSelector(ctx context.Context, namespace string, labelSelector string) ([]TargetInfo, error) {
+	resp, err := c.listTargets(ctx, namespace, labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TargetInfo, 0, len(resp.Items))
+
+	for _, i := range resp.Items {
+		ti := TargetInfo{
+			Namespace: i.Namespace,
+			Name:      i.Name,
+			Provider:  i.Spec.Provider,
+			Address:   i.Spec.Address,
+			Connected: i.GetCondition(invv1alpha1.ConditionTypeTargetConnectionReady).IsTrue(),
+			Ready:     i.IsReady(),
+		}
+		if !ti.Ready {
+			ti.Reason = i.NotReadyReason()
+		}
+		result = append(result, ti)
+	}
+
+	return result, nil
+}
+
+// NamespaceInfo counts the targets and configs of a namespace
+type NamespaceInfo struct {
+	Namespace string `json:"namespace"`
+	Targets   int    `json:"targets"`
+	Configs   int    `json:"configs"`
+}
+
+// ListNamespaces returns the namespaces holding targets or configs, sorted by name
+func (c *ConfigClient) ListNamespaces(ctx context.Context) ([]NamespaceInfo, error) {
+	targets, err := c.ListTargetRefs(ctx, v1.NamespaceAll)
+	if err != nil {
+		return nil, err
+	}
+	configs, err := c.ListConfigInfos(ctx, v1.NamespaceAll, "")
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := map[string]*NamespaceInfo{}
+	namespace := func(name string) *NamespaceInfo {
+		ni, exists := namespaces[name]
+		if !exists {
+			ni = &NamespaceInfo{Namespace: name}
+			namespaces[name] = ni
+		}
+		return ni
+	}
+	for _, t := range targets {
+		namespace(t.Namespace).Targets++
+	}
+	for _, cfg := range configs {
+		namespace(cfg.Namespace).Configs++
+	}
+
+	result := make([]NamespaceInfo, 0, len(namespaces))
+	for _, ni := range namespaces {
+		result = append(result, *ni)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Namespace < result[j].Namespace })
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	configv1alpha1 "github.com/sdcio/config-server/apis/config/v1alpha1"
+	"github.com/sdcio/config-server/pkg/generated/clientset/versioned/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// failingReactor fails the first failures calls with err and then lets the
+// fake clientset handle them, counting all calls
+func failingReactor(failures int, err error, calls *int) k8stesting.ReactionFunc {
+	return func(k8stesting.Action) (bool, runtime.Object, error) {
+		*calls++
+		if *calls <= failures {
+			return true, nil, err
+		}
+		return false, nil, nil
+	}
+}
+
+func newTestConfig(name string) *configv1alpha1.Config {
+	return &configv1alpha1.Config{
+		ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: name},
+		Spec:       configv1alpha1.ConfigSpec{Priority: 10},
+	}
+}
+
+func TestApplyConfigRetriesTransientErrors(t *testing.T) {
+	cs := fake.NewSimpleClientset()
+	calls := 0
+	// the simple fake tracker does not implement server-side apply, so the
+	// reactor answers the patch itself once it stops failing
+	cs.PrependReactor("patch", "configs", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		if handled, _, err := failingReactor(1, apierrors.NewServiceUnavailable("try again"), &calls)(a); handled {
+			return true, nil, err
+		}
+		return true, newTestConfig(a.(k8stesting.PatchAction).GetName()), nil
+	})
+	c := &ConfigClient{c: cs, retries: 1}
+
+	applied, err := c.ApplyConfig(context.Background(), newTestConfig("srl1"))
+	if err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	if applied.Name != "srl1" {
+		t.Errorf("applied config %q, want srl1", applied.Name)
+	}
+	if calls != 2 {
+		t.Errorf("patch called %d times, want 2", calls)
+	}
+}
+
+func TestGetConfigRetriesTransientErrors(t *testing.T) {
+	cs := fake.NewSimpleClientset(newTestConfig("srl1"))
+	calls := 0
+	cs.PrependReactor("get", "configs", failingReactor(2, apierrors.NewTooManyRequests("slow down", 0), &calls))
+	c := &ConfigClient{c: cs, retries: 2}
+
+	cfg, err := c.GetConfig(context.Background(), "default", "srl1")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if cfg.Name != "srl1" {
+		t.Errorf("got config %q, want srl1", cfg.Name)
+	}
+	if calls != 3 {
+		t.Errorf("get called %d times, want 3", calls)
+	}
+}
+
+func TestGetConfigDoesNotRetryNotFound(t *testing.T) {
+	cs := fake.NewSimpleClientset()
+	calls := 0
+	cs.PrependReactor("get", "configs", failingReactor(0, nil, &calls))
+	c := &ConfigClient{c: cs, retries: 3}
+
+	_, err := c.GetConfig(context.Background(), "default", "srl1")
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected a NotFound error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("get called %d times, want 1", calls)
+	}
+}
+
+func TestListCallsRetryTransientErrors(t *testing.T) {
+	cs := fake.NewSimpleClientset(newTestConfig("srl1"))
+	calls := map[string]int{}
+	for _, resource := range []string{"configs", "configblames", "targets"} {
+		count := 0
+		cs.PrependReactor("list", resource, func(a k8stesting.Action) (bool, runtime.Object, error) {
+			handled, obj, err := failingReactor(1, apierrors.NewInternalError(context.DeadlineExceeded), &count)(a)
+			calls[resource] = count
+			return handled, obj, err
+		})
+	}
+	c := &ConfigClient{c: cs, retries: 1}
+	ctx := context.Background()
+
+	if _, err := c.ListBlameTrees(ctx, "default"); err != nil {
+		t.Errorf("listing blame trees: %v", err)
+	}
+	namespaces, err := c.ListNamespaces(ctx)
+	if err != nil {
+		t.Fatalf("listing namespaces: %v", err)
+	}
+	if len(namespaces) != 1 || namespaces[0].Configs != 1 {
+		t.Errorf("got namespaces %v, want default with one config", namespaces)
+	}
+	for _, resource := range []string{"configs", "configblames", "targets"} {
+		if calls[resource] != 2 {
+			t.Errorf("%s listed %d times, want 2", resource, calls[resource])
+		}
+	}
+}
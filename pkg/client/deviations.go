@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+)
+
+// DefaultConcurrency is the number of devices processed in parallel when no
+// explicit concurrency is given
+const DefaultConcurrency = 8
+
+// BlameDeviation is a path whose value on the device deviates from the intended value
+type BlameDeviation struct {
+	Path           string `json:"path"`
+	Owner          string `json:"owner"`
+	Value          string `json:"value"`
+	DeviationValue string `json:"deviationValue"`
+}
+
+// GetAllDeviations collects the deviated paths of all devices in the namespace,
+// keyed by device. Up to concurrency devices are fetched in parallel.
+// Devices that fail are left out of the report and their errors are returned
+// joined, alongside the partial report.
+func (c *ConfigClient) GetAllDeviations(ctx context.Context, namespace string, concurrency int) (map[string][]BlameDeviation, error) {
+	devices, err := c.GetTargetNames(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		m      sync.Mutex
+		report = make(map[string][]BlameDeviation, len(devices))
+	)
+	deviceErrs := forEachDevice(ctx, devices, concurrency, func(device string) error {
+		devs, err := c.getDeviations(ctx, namespace, device)
+		if err != nil {
+			return err
+		}
+		m.Lock()
+		report[device] = devs
+		m.Unlock()
+		return nil
+	})
+
+	return report, joinDeviceErrors(deviceErrs)
+}
+
+func (c *ConfigClient) getDeviations(ctx context.Context, namespace string, device string) ([]BlameDeviation, error) {
+	result := []BlameDeviation{}
+	err := c.WalkFilteredBlameTree(ctx, namespace, device, BlameFilter{Deviation: true}, func(path string, e *sdcpb.BlameTreeElement) error {
+		result = append(result, BlameDeviation{
+			Path:           path,
+			Owner:          e.GetOwner(),
+			Value:          blameValue(e),
+			DeviationValue: e.GetDeviationValue().ToString(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	configv1alpha1 "github.com/sdcio/config-server/apis/config/v1alpha1"
+	"github.com/sdcio/config-server/pkg/generated/clientset/versioned/fake"
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestGetAllDeviations(t *testing.T) {
+	devices := []string{"srl1", "srl2", "srl3"}
+	c := newTestFleetClient(t, devices, "srl2")
+	// srl3 deviates from its intent on its only leaf
+	bte := newTestBlameTree([]string{"system", "srl3"})
+	leaf, _, err := findBlameNode(bte, "/system/srl3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf.DeviationValue = &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "running"}}
+	setTestBlameTree(t, c, "srl3", bte)
+
+	for _, concurrency := range []int{0, 1, 2} {
+		report, err := c.GetAllDeviations(context.Background(), "default", concurrency)
+
+		if !apierrors.IsNotFound(err) || !strings.HasPrefix(err.Error(), "device srl2: ") {
+			t.Errorf("concurrency %d: got error %v, want the NotFound error of srl2", concurrency, err)
+		}
+		if _, ok := report["srl2"]; ok {
+			t.Errorf("concurrency %d: failed device srl2 is in the report", concurrency)
+		}
+		if devs, ok := report["srl1"]; !ok || len(devs) != 0 {
+			t.Errorf("concurrency %d: got srl1 deviations %v, want an empty list", concurrency, devs)
+		}
+		want := []BlameDeviation{{Path: "/system/srl3", Owner: "default.intent", Value: "x", DeviationValue: "running"}}
+		if devs := report["srl3"]; len(devs) != 1 || devs[0] != want[0] {
+			t.Errorf("concurrency %d: got srl3 deviations %v, want %v", concurrency, devs, want)
+		}
+	}
+}
+
+func TestGetAllDeviationsListFails(t *testing.T) {
+	c := newTestFleetClient(t, []string{"srl1"})
+	c.c.(*fake.Clientset).PrependReactor("list", "targets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(configv1alpha1.Resource("targets"), "", errors.New("denied"))
+	})
+
+	report, err := c.GetAllDeviations(context.Background(), "default", 1)
+	if !apierrors.IsForbidden(err) {
+		t.Errorf("got error %v, want Forbidden", err)
+	}
+	if report != nil {
+		t.Errorf("got report %v, want none", report)
+	}
+}
+
+// setTestBlameTree replaces the blame tree the fake clientset serves for
+// device
+func setTestBlameTree(t *testing.T, c *ConfigClient, device string, bte *sdcpb.BlameTreeElement) {
+	t.Helper()
+	raw, err := protojson.Marshal(bte)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blames := c.c.ConfigV1alpha1().ConfigBlames("default")
+	cb, err := blames.Get(context.Background(), device, v1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb.Status.Value = runtime.RawExtension{Raw: raw}
+	if _, err := blames.Update(context.Background(), cb, v1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,317 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sdcio/config-server/apis/config"
+	configv1alpha1 "github.com/sdcio/config-server/apis/config/v1alpha1"
+	"github.com/sdcio/kubectl-sdcio/pkg/utils"
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// IntentDiffKind classifies a difference between a Config intent and the blame tree
+type IntentDiffKind string
+
+const (
+	// IntentMissing is a path set by the intent that is not present on the device
+	IntentMissing IntentDiffKind = "missing"
+	// IntentNotOwned is a path set by the intent that is owned by another intent,
+	// e.g. one with a higher priority
+	IntentNotOwned IntentDiffKind = "not-owned"
+	// IntentDeviated is a path owned by the intent whose device value deviates
+	IntentDeviated IntentDiffKind = "deviated"
+)
+
+// IntentDiffEntry is a single path of a Config intent that is not applied as intended
+type IntentDiffEntry struct {
+	Config   string         `json:"config"`
+	Path     string         `json:"path"`
+	Kind     IntentDiffKind `json:"kind"`
+	Intended string         `json:"intended,omitempty"`
+	// Actual is the value found on the device
+	Actual string `json:"actual,omitempty"`
+	// Owner is the owner found in the blame tree
+	Owner string `json:"owner,omitempty"`
+}
+
+// IntentDiffReport lists the paths of the Configs of a device that are not
+// applied as intended
+type IntentDiffReport struct {
+	Device  string            `json:"device"`
+	Configs []string          `json:"configs"`
+	Entries []IntentDiffEntry `json:"entries"`
+}
+
+// DiffIntentVsApplied compares the intent of every Config targeting the device
+// with the device's blame tree, reporting the paths that are missing on the
+// device, owned by another intent or deviating.
+func (c *ConfigClient) DiffIntentVsApplied(ctx context.Context, namespace string, device string) (*IntentDiffReport, error) {
+	selector := labels.SelectorFromSet(labels.Set{config.TargetNameKey: device}).String()
+	configs, err := c.ListConfigInfos(ctx, namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	bte, err := c.GetBlameTree(ctx, namespace, device)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &IntentDiffReport{Device: device, Configs: []string{}, Entries: []IntentDiffEntry{}}
+	for _, ci := range configs {
+		cfg, err := c.GetConfig(ctx, ci.Namespace, ci.Name)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := diffIntent(cfg, bte)
+		if err != nil {
+			return nil, err
+		}
+		report.Configs = append(report.Configs, cfg.Name)
+		report.Entries = append(report.Entries, entries...)
+	}
+	return report, nil
+}
+
+// diffIntent compares the intent of cfg with the blame tree bte
+func diffIntent(cfg *configv1alpha1.Config, bte *sdcpb.BlameTreeElement) ([]IntentDiffEntry, error) {
+	intents, err := DecodeConfigIntent(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &intentDiffer{
+		config: cfg.Name,
+		owner:  configOwner(cfg.Namespace, cfg.Name),
+	}
+	for _, intent := range intents {
+		node, path, err := findBlameNode(bte, intent.Path)
+		if err != nil {
+			return nil, fmt.Errorf("config %s/%s: %w", cfg.Namespace, cfg.Name, err)
+		}
+		if node == nil {
+			d.add(path, IntentMissing, intent.Value, nil)
+			continue
+		}
+		d.compare(node, path, intent.Value)
+	}
+	return d.entries, nil
+}
+
+// findBlameNode returns the node of the tree addressed by the xpath p,
+// along with the blame path of the node. If the node does not exist, the
+// path up to the first missing element is returned with a nil node.
+func findBlameNode(bte *sdcpb.BlameTreeElement, p string) (*sdcpb.BlameTreeElement, string, error) {
+	chain, path, err := findBlameChain(bte, p)
+	if err != nil || chain == nil {
+		return nil, path, err
+	}
+	return chain[len(chain)-1], path, nil
+}
+
+// findBlameChain is findBlameNode returning the elements from bte down to
+// the addressed node, or nil if the node does not exist
+func findBlameChain(bte *sdcpb.BlameTreeElement, p string) ([]*sdcpb.BlameTreeElement, string, error) {
+	chain := []*sdcpb.BlameTreeElement{bte}
+	path := ""
+	// key values may hold slashes, e.g. prefixes, so the segments are split
+	// before being parsed one by one
+	for _, segment := range utils.SplitPath(p) {
+		sp, err := sdcpb.ParsePath("/" + segment)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid path %q: %w", p, err)
+		}
+
+		for _, pe := range sp.GetElem() {
+			// list entries are nested below the list by the value of each
+			// key. The data-server orders these levels by key name, not by
+			// the order of the key statement of the schema, see
+			// sdcpb.PathElem.PathElemNames, which is why no schema is
+			// needed here. Module prefixes are stripped from the names but
+			// not from the key values, which may hold colons themselves,
+			// e.g. IPv6 addresses.
+			names := []string{utils.LocalName(pe.GetName())}
+			keys := make([]string, 0, len(pe.GetKey()))
+			for k := range pe.GetKey() {
+				keys = append(keys, k)
+			}
+			slices.SortFunc(keys, func(a, b string) int { return strings.Compare(utils.LocalName(a), utils.LocalName(b)) })
+			for _, k := range keys {
+				names = append(names, pe.GetKey()[k])
+			}
+
+			for _, name := range names {
+				path = blamePath(path, name)
+				bte = blameChild(bte, name)
+				if bte == nil {
+					klog.V(3).InfoS("Path not found in blame tree", "path", p, "missing", path)
+					return nil, path, nil
+				}
+				chain = append(chain, bte)
+			}
+		}
+	}
+	return chain, path, nil
+}
+
+// blameChild returns the child of bte with the given name, or nil
+func blameChild(bte *sdcpb.BlameTreeElement, name string) *sdcpb.BlameTreeElement {
+	for _, c := range bte.GetChilds() {
+		if c.GetName() == name {
+			return c
+		}
+	}
+	return nil
+}
+
+type intentDiffer struct {
+	config  string
+	owner   string
+	entries []IntentDiffEntry
+}
+
+func (d *intentDiffer) add(path string, kind IntentDiffKind, intended any, node *sdcpb.BlameTreeElement) {
+	e := IntentDiffEntry{
+		Config:   d.config,
+		Path:     path,
+		Kind:     kind,
+		Intended: intentValueString(intended),
+	}
+	if node != nil {
+		e.Owner = node.GetOwner()
+		e.Actual = blameValue(node)
+		if node.IsDeviated() {
+			e.Actual = node.GetDeviationValue().ToString()
+		}
+	}
+	d.entries = append(d.entries, e)
+}
+
+// compare walks the decoded intent value alongside the blame node
+func (d *intentDiffer) compare(node *sdcpb.BlameTreeElement, path string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		names := make([]string, 0, len(v))
+		for k := range v {
+			names = append(names, k)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			child := blameChild(node, name)
+			if child == nil {
+				// RFC 7951 qualifies members with their module name, the
+				// blame tree uses the local names
+				child = blameChild(node, utils.LocalName(name))
+			}
+			p := blamePath(path, utils.LocalName(name))
+			if child == nil {
+				d.add(p, IntentMissing, v[name], nil)
+				continue
+			}
+			d.compare(child, p, v[name])
+		}
+	case []any:
+		if node.GetValue() != nil {
+			// leaf-list
+			d.compareLeaf(node, path, value)
+			return
+		}
+		for _, item := range v {
+			entry, ok := item.(map[string]any)
+			if !ok {
+				d.add(path, IntentMissing, item, nil)
+				continue
+			}
+			d.compareListEntry(node, path, entry)
+		}
+	default:
+		d.compareLeaf(node, path, value)
+	}
+}
+
+// compareListEntry locates the list entry by descending into the children
+// named after the entry's key values. The schema is not known here, so every
+// scalar member of the entry is a key candidate.
+func (d *intentDiffer) compareListEntry(list *sdcpb.BlameTreeElement, path string, entry map[string]any) {
+	members := make([]string, 0, len(entry))
+	for k := range entry {
+		members = append(members, k)
+	}
+	slices.Sort(members)
+
+	node := list
+	used := map[string]bool{}
+	for {
+		var next *sdcpb.BlameTreeElement
+		for _, k := range members {
+			if used[k] || isComposite(entry[k]) {
+				continue
+			}
+			if c := blameChild(node, intentValueString(entry[k])); c != nil {
+				next = c
+				used[k] = true
+				path = blamePath(path, c.GetName())
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	if node == list {
+		d.add(path, IntentMissing, entry, nil)
+		return
+	}
+
+	rest := make(map[string]any, len(entry))
+	for k, v := range entry {
+		if !used[k] {
+			rest[k] = v
+		}
+	}
+	d.compare(node, path, rest)
+}
+
+func (d *intentDiffer) compareLeaf(node *sdcpb.BlameTreeElement, path string, value any) {
+	switch {
+	case node.GetOwner() != d.owner:
+		d.add(path, IntentNotOwned, value, node)
+	case node.IsDeviated():
+		d.add(path, IntentDeviated, value, node)
+	}
+}
+
+func isComposite(v any) bool {
+	switch v.(type) {
+	case map[string]any, []any:
+		return true
+	}
+	return false
+}
+
+// intentValueString formats a decoded JSON value the way it appears in the blame tree
+func intentValueString(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
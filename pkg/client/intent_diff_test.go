@@ -0,0 +1,177 @@
+package client
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	configv1alpha1 "github.com/sdcio/config-server/apis/config/v1alpha1"
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// newTestBlameTree returns a tree holding the leaves, given as element
+// names from the root, all with the same owner and value
+func newTestBlameTree(leaves ...[]string) *sdcpb.BlameTreeElement {
+	root := &sdcpb.BlameTreeElement{Name: "root"}
+	for _, leaf := range leaves {
+		node := root
+		for _, name := range leaf {
+			child := blameChild(node, name)
+			if child == nil {
+				child = &sdcpb.BlameTreeElement{Name: name}
+				node.Childs = append(node.Childs, child)
+			}
+			node = child
+		}
+		node.Owner = "default.intent"
+		node.Value = &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "x"}}
+	}
+	return root
+}
+
+func TestFindBlameNode(t *testing.T) {
+	bte := newTestBlameTree(
+		[]string{"interface", "ethernet-1/1", "mtu"},
+		[]string{"network-instance", "default", "route", "10.0.0.0/8", "static", "metric"},
+		[]string{"neighbor", "2001:db8::1", "peer-as"},
+	)
+	tests := []struct {
+		path      string
+		wantFound bool
+		wantPath  string
+	}{
+		{"/interface[name=ethernet-1/1]/mtu", true, "/interface/ethernet-1/1/mtu"},
+		{"interface[name=ethernet-1/1]", true, "/interface/ethernet-1/1"},
+		// key values nest in the order of the key names, prefix before type,
+		// whatever the order of the keys in the path or the schema
+		{"/network-instance[name=default]/route[type=static][prefix=10.0.0.0/8]/metric", true, "/network-instance/default/route/10.0.0.0/8/static/metric"},
+		{"/network-instance[name=default]/route[prefix=10.0.0.0/8][type=static]/metric", true, "/network-instance/default/route/10.0.0.0/8/static/metric"},
+		{"/srl:interface[name=ethernet-1/1]/mtu", true, "/interface/ethernet-1/1/mtu"},
+		{"/bgp:neighbor[bgp:address=2001:db8::1]/peer-as", true, "/neighbor/2001:db8::1/peer-as"},
+		{"/interface[name=ethernet-1/2]/mtu", false, "/interface/ethernet-1/2"},
+	}
+	for _, tt := range tests {
+		node, path, err := findBlameNode(bte, tt.path)
+		if err != nil {
+			t.Errorf("findBlameNode(%q): %v", tt.path, err)
+			continue
+		}
+		if (node != nil) != tt.wantFound {
+			t.Errorf("findBlameNode(%q) found %v, want %v", tt.path, node != nil, tt.wantFound)
+		}
+		if path != tt.wantPath {
+			t.Errorf("findBlameNode(%q) path %q, want %q", tt.path, path, tt.wantPath)
+		}
+	}
+}
+
+func TestDiffIntent(t *testing.T) {
+	// the nodes owned by default.intent hold "x", the Config under test is
+	// default/intent
+	newTree := func() *sdcpb.BlameTreeElement {
+		bte := newTestBlameTree(
+			[]string{"system", "name"},
+			[]string{"system", "location"},
+			[]string{"system", "dns", "server"},
+			[]string{"interface", "ethernet-1/1", "name"},
+			[]string{"interface", "ethernet-1/1", "mtu"},
+			[]string{"route", "10.0.0.0/8", "static", "metric"},
+		)
+		location, _, _ := findBlameNode(bte, "/system/location")
+		location.Owner = "default.other"
+		name, _, _ := findBlameNode(bte, "/system/name")
+		name.DeviationValue = &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "y"}}
+		metric, _, _ := findBlameNode(bte, "/route[prefix=10.0.0.0/8][type=static]/metric")
+		metric.DeviationValue = &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "y"}}
+		return bte
+	}
+	tests := []struct {
+		name   string
+		path   string
+		intent string
+		want   []IntentDiffEntry
+	}{
+		{
+			name:   "applied leaf",
+			path:   "/interface[name=ethernet-1/1]",
+			intent: `{"mtu": "x"}`,
+		},
+		{
+			name:   "missing leaf",
+			path:   "/",
+			intent: `{"system": {"contact": "noc"}}`,
+			want:   []IntentDiffEntry{{Path: "/system/contact", Kind: IntentMissing, Intended: "noc"}},
+		},
+		{
+			name:   "missing intent path",
+			path:   "/interface[name=ethernet-1/2]",
+			intent: `{"mtu": 1500}`,
+			want:   []IntentDiffEntry{{Path: "/interface/ethernet-1/2", Kind: IntentMissing, Intended: `{"mtu":1500}`}},
+		},
+		{
+			name:   "leaf owned by another config",
+			path:   "/system",
+			intent: `{"location": "lab"}`,
+			want:   []IntentDiffEntry{{Path: "/system/location", Kind: IntentNotOwned, Intended: "lab", Actual: "x", Owner: "default.other"}},
+		},
+		{
+			name:   "deviated leaf",
+			path:   "/",
+			intent: `{"srl:system": {"name": "x"}}`,
+			want:   []IntentDiffEntry{{Path: "/system/name", Kind: IntentDeviated, Intended: "x", Actual: "y", Owner: "default.intent"}},
+		},
+		{
+			name:   "leaf-list",
+			path:   "/system/dns",
+			intent: `{"server": ["1.1.1.1", "8.8.8.8"]}`,
+		},
+		{
+			name:   "list entry found by its key",
+			path:   "/",
+			intent: `{"interface": [{"name": "ethernet-1/1", "mtu": "x"}]}`,
+		},
+		{
+			name:   "missing list entry",
+			path:   "/",
+			intent: `{"interface": [{"name": "ethernet-1/2", "mtu": 1500}]}`,
+			want:   []IntentDiffEntry{{Path: "/interface", Kind: IntentMissing, Intended: `{"mtu":1500,"name":"ethernet-1/2"}`}},
+		},
+		{
+			name:   "list entry with two keys",
+			path:   "/",
+			intent: `{"route": [{"type": "static", "prefix": "10.0.0.0/8", "metric": "x"}]}`,
+			want:   []IntentDiffEntry{{Path: "/route/10.0.0.0/8/static/metric", Kind: IntentDeviated, Intended: "x", Actual: "y", Owner: "default.intent"}},
+		},
+		{
+			name:   "list entry addressed by two keys in the path",
+			path:   "/route[type=static][prefix=10.0.0.0/8]",
+			intent: `{"metric": "x"}`,
+			want:   []IntentDiffEntry{{Path: "/route/10.0.0.0/8/static/metric", Kind: IntentDeviated, Intended: "x", Actual: "y", Owner: "default.intent"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &configv1alpha1.Config{
+				ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: "intent"},
+				Spec: configv1alpha1.ConfigSpec{Config: []configv1alpha1.ConfigBlob{
+					{Path: tt.path, Value: runtime.RawExtension{Raw: json.RawMessage(tt.intent)}},
+				}},
+			}
+			got, err := diffIntent(cfg, newTree())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range tt.want {
+				tt.want[i].Config = "intent"
+			}
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffIntent() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+)
+
+// forEachDevice calls fn for every device, running up to concurrency calls in
+// parallel. Devices not yet started when ctx is cancelled fail with the
+// context error. The errors are returned per device.
+func forEachDevice(ctx context.Context, devices []string, concurrency int, fn func(device string) error) map[string]error {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	var (
+		m    sync.Mutex
+		wg   sync.WaitGroup
+		errs = map[string]error{}
+		work = make(chan string)
+	)
+
+	for range min(concurrency, len(devices)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for device := range work {
+				err := ctx.Err()
+				if err == nil {
+					err = fn(device)
+				}
+				if err != nil {
+					m.Lock()
+					errs[device] = err
+					m.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, device := range devices {
+		work <- device
+	}
+	close(work)
+	wg.Wait()
+
+	return errs
+}
+
+// BlameTreeResult holds the blame trees of several devices, keyed by device
+type BlameTreeResult struct {
+	Trees  map[string]*sdcpb.BlameTreeElement
+	Errors map[string]error
+}
+
+// GetBlameTrees fetches the blame trees of the devices, up to concurrency in
+// parallel. A device that fails does not fail the batch, its error is
+// reported in the result instead.
+func (c *ConfigClient) GetBlameTrees(ctx context.Context, namespace string, devices []string, concurrency int) *BlameTreeResult {
+	var m sync.Mutex
+	result := &BlameTreeResult{Trees: make(map[string]*sdcpb.BlameTreeElement, len(devices))}
+	result.Errors = forEachDevice(ctx, devices, concurrency, func(device string) error {
+		bte, err := c.GetBlameTree(ctx, namespace, device)
+		if err != nil {
+			return err
+		}
+		m.Lock()
+		result.Trees[device] = bte
+		m.Unlock()
+		return nil
+	})
+	return result
+}
+
+// joinDeviceErrors joins the per device errors in device order
+func joinDeviceErrors(deviceErrs map[string]error) error {
+	devices := make([]string, 0, len(deviceErrs))
+	for device := range deviceErrs {
+		devices = append(devices, device)
+	}
+	slices.Sort(devices)
+
+	var errs error
+	for _, device := range devices {
+		errs = errors.Join(errs, fmt.Errorf("device %s: %w", device, deviceErrs[device]))
+	}
+	return errs
+}
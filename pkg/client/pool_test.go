@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	configv1alpha1 "github.com/sdcio/config-server/apis/config/v1alpha1"
+	invv1alpha1 "github.com/sdcio/config-server/apis/inv/v1alpha1"
+	"github.com/sdcio/config-server/pkg/generated/clientset/versioned/fake"
+	"google.golang.org/protobuf/encoding/protojson"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestFleetClient returns a client serving a target and a ConfigBlame in
+// the default namespace for every device, the blame tree of each device
+// holding a single leaf named after the device. Getting the ConfigBlame of a
+// device in failing fails with a NotFound error.
+func newTestFleetClient(t *testing.T, devices []string, failing ...string) *ConfigClient {
+	t.Helper()
+	objects := []runtime.Object{}
+	for _, device := range devices {
+		raw, err := protojson.Marshal(newTestBlameTree([]string{"system", device}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		objects = append(objects,
+			&invv1alpha1.Target{ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: device}},
+			&configv1alpha1.ConfigBlame{
+				ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: device},
+				Status:     configv1alpha1.ConfigBlameStatus{Value: runtime.RawExtension{Raw: raw}},
+			},
+		)
+	}
+	cs := fake.NewSimpleClientset(objects...)
+	cs.PrependReactor("get", "configblames", func(a k8stesting.Action) (bool, runtime.Object, error) {
+		name := a.(k8stesting.GetAction).GetName()
+		for _, device := range failing {
+			if name == device {
+				return true, nil, apierrors.NewNotFound(configv1alpha1.Resource("configblames"), name)
+			}
+		}
+		return false, nil, nil
+	})
+	return &ConfigClient{c: cs}
+}
+
+func TestGetBlameTreesPartialFailure(t *testing.T) {
+	devices := []string{"srl1", "srl2", "srl3", "srl4"}
+	c := newTestFleetClient(t, devices, "srl2", "srl4")
+
+	for _, concurrency := range []int{0, 1, 2, 10} {
+		result := c.GetBlameTrees(context.Background(), "default", devices, concurrency)
+
+		if len(result.Trees) != 2 {
+			t.Errorf("concurrency %d: got %d trees, want 2", concurrency, len(result.Trees))
+		}
+		// each tree is stored under the device it was fetched for
+		for _, device := range []string{"srl1", "srl3"} {
+			if _, _, err := findBlameNode(result.Trees[device], "/system/"+device); err != nil {
+				t.Errorf("concurrency %d: tree of %s: %v", concurrency, device, err)
+			}
+		}
+		if len(result.Errors) != 2 {
+			t.Errorf("concurrency %d: got errors %v, want srl2 and srl4", concurrency, result.Errors)
+		}
+		for _, device := range []string{"srl2", "srl4"} {
+			if !apierrors.IsNotFound(result.Errors[device]) {
+				t.Errorf("concurrency %d: error of %s is %v, want NotFound", concurrency, device, result.Errors[device])
+			}
+			if _, ok := result.Trees[device]; ok {
+				t.Errorf("concurrency %d: failed device %s has a tree", concurrency, device)
+			}
+		}
+	}
+}
+
+func TestForEachDeviceConcurrency(t *testing.T) {
+	devices := []string{"srl1", "srl2", "srl3", "srl4", "srl5", "srl6"}
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{}, len(devices))
+
+	done := make(chan map[string]error)
+	go func() {
+		done <- forEachDevice(context.Background(), devices, 2, func(string) error {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			started <- struct{}{}
+			<-release
+			running.Add(-1)
+			return nil
+		})
+	}()
+
+	// let two workers block, then release all of them
+	<-started
+	<-started
+	close(release)
+	if errs := <-done; len(errs) != 0 {
+		t.Errorf("got errors %v, want none", errs)
+	}
+	if peak.Load() != 2 {
+		t.Errorf("ran %d devices in parallel, want 2", peak.Load())
+	}
+}
+
+func TestForEachDeviceCancelled(t *testing.T) {
+	devices := []string{"srl1", "srl2", "srl3"}
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+
+	errs := forEachDevice(ctx, devices, 1, func(device string) error {
+		calls++
+		cancel()
+		return nil
+	})
+
+	if calls != 1 {
+		t.Errorf("fn called %d times, want 1", calls)
+	}
+	for _, device := range []string{"srl2", "srl3"} {
+		if !errors.Is(errs[device], context.Canceled) {
+			t.Errorf("error of %s is %v, want context.Canceled", device, errs[device])
+		}
+	}
+	if _, ok := errs["srl1"]; ok {
+		t.Errorf("started device srl1 reports %v", errs["srl1"])
+	}
+}
+
+func TestJoinDeviceErrors(t *testing.T) {
+	errBoom := errors.New("boom")
+	err := joinDeviceErrors(map[string]error{
+		"srl3":  errBoom,
+		"leaf1": errors.New("unreachable"),
+		"srl10": errors.New("timeout"),
+	})
+
+	want := "device leaf1: unreachable\ndevice srl10: timeout\ndevice srl3: boom"
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
+	}
+	if !errors.Is(err, errBoom) {
+		t.Errorf("joined error does not wrap the device error")
+	}
+	if err := joinDeviceErrors(map[string]error{}); err != nil {
+		t.Errorf("got %v for no errors, want nil", err)
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+// DefaultRetries is the number of times a failed API call is retried
+const DefaultRetries = 3
+
+// retryDelay is the delay before the first retry, doubled for each further one
+var retryDelay = 200 * time.Millisecond
+
+// backoff returns the backoff used to retry API calls
+func (c *ConfigClient) backoff() wait.Backoff {
+	return wait.Backoff{
+		Steps:    c.retries + 1,
+		Duration: retryDelay,
+		Factor:   2.0,
+		Jitter:   0.1,
+		Cap:      5 * time.Second,
+	}
+}
+
+// withRetry calls fn and retries it with exponential backoff as long as it
+// returns transient errors. It stops as soon as ctx is done and returns the
+// error of ctx then.
+func (c *ConfigClient) withRetry(ctx context.Context, fn func() error) error {
+	return retry.OnError(c.backoff(), func(err error) bool {
+		if ctx.Err() != nil || !isRetriable(err) {
+			return false
+		}
+		klog.V(1).InfoS("Retrying after transient error", "err", err)
+		return true
+	}, func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn()
+	})
+}
+
+// isRetriable returns true for errors that are likely to go away when retrying.
+// An expired or cancelled context is not, even though context.DeadlineExceeded
+// is a net.Error reporting a timeout.
+func isRetriable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsInternalError(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsUnexpectedServerError(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestMain(m *testing.M) {
+	// keep the retrying tests fast
+	retryDelay = 0
+	os.Exit(m.Run())
+}
+
+// timeoutError is a net.Error reporting a timeout
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var _ net.Error = timeoutError{}
+
+var configsGR = schema.GroupResource{Group: "config.sdcio.dev", Resource: "configs"}
+
+func TestIsRetriable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server timeout", apierrors.NewServerTimeout(configsGR, "get", 1), true},
+		{"timeout", apierrors.NewTimeoutError("timeout", 1), true},
+		{"too many requests", apierrors.NewTooManyRequests("slow down", 1), true},
+		{"internal error", apierrors.NewInternalError(errors.New("boom")), true},
+		{"service unavailable", apierrors.NewServiceUnavailable("down"), true},
+		{"network timeout", timeoutError{}, true},
+		{"wrapped network timeout", fmt.Errorf("get: %w", timeoutError{}), true},
+		{"not found", apierrors.NewNotFound(configsGR, "srl1"), false},
+		{"forbidden", apierrors.NewForbidden(configsGR, "srl1", errors.New("rbac")), false},
+		{"conflict", apierrors.NewConflict(configsGR, "srl1", errors.New("changed")), false},
+		{"invalid", apierrors.NewBadRequest("bad"), false},
+		{"cancelled", context.Canceled, false},
+		{"deadline exceeded", context.DeadlineExceeded, false},
+		{"wrapped deadline exceeded", fmt.Errorf("get: %w", context.DeadlineExceeded), false},
+		{"plain error", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetriable(tt.err); got != tt.want {
+				t.Errorf("isRetriable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	unavailable := apierrors.NewServiceUnavailable("down")
+	notFound := apierrors.NewNotFound(configsGR, "srl1")
+	tests := []struct {
+		name      string
+		retries   int
+		failures  int
+		err       error
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "success", retries: 3, failures: 0, err: unavailable, wantCalls: 1},
+		{name: "transient error recovered", retries: 3, failures: 2, err: unavailable, wantCalls: 3},
+		{name: "transient error exhausting the retries", retries: 3, failures: 10, err: unavailable, wantCalls: 4, wantErr: unavailable},
+		{name: "no retries", retries: 0, failures: 1, err: unavailable, wantCalls: 1, wantErr: unavailable},
+		{name: "permanent error", retries: 3, failures: 10, err: notFound, wantCalls: 1, wantErr: notFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ConfigClient{}
+			c.SetRetries(tt.retries)
+			calls := 0
+			err := c.withRetry(context.Background(), func() error {
+				calls++
+				if calls <= tt.failures {
+					return tt.err
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWithRetryCancelled(t *testing.T) {
+	unavailable := apierrors.NewServiceUnavailable("down")
+	t.Run("done before the first call", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		c := &ConfigClient{retries: 3}
+		calls := 0
+		err := c.withRetry(ctx, func() error {
+			calls++
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got error %v, want %v", err, context.Canceled)
+		}
+		if calls != 0 {
+			t.Errorf("called %d times, want 0", calls)
+		}
+	})
+	t.Run("cancelled during a call", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		c := &ConfigClient{retries: 3}
+		calls := 0
+		err := c.withRetry(ctx, func() error {
+			calls++
+			cancel()
+			return unavailable
+		})
+		if !errors.Is(err, unavailable) {
+			t.Errorf("got error %v, want %v", err, unavailable)
+		}
+		if calls != 1 {
+			t.Errorf("called %d times, want 1", calls)
+		}
+	})
+	t.Run("deadline exceeded", func(t *testing.T) {
+		c := &ConfigClient{retries: 3}
+		calls := 0
+		err := c.withRetry(context.Background(), func() error {
+			calls++
+			return fmt.Errorf("get: %w", context.DeadlineExceeded)
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+		}
+		if calls != 1 {
+			t.Errorf("called %d times, want 1", calls)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	configv1alpha1 "github.com/sdcio/config-server/apis/config/v1alpha1"
+	invv1alpha1 "github.com/sdcio/config-server/apis/inv/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+// expectedResources are the resources the plugin relies on
+var expectedResources = []schema.GroupVersionResource{
+	configv1alpha1.SchemeGroupVersion.WithResource("configs"),
+	configv1alpha1.SchemeGroupVersion.WithResource("configblames"),
+	invv1alpha1.SchemeGroupVersion.WithResource("targets"),
+}
+
+// ServerInfo describes the config-server API as seen through discovery
+type ServerInfo struct {
+	// KubernetesVersion is the version of the kubernetes API server
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// Groups maps the sdcio API groups to their served versions
+	Groups map[string][]string `json:"groups"`
+	// Resources lists the resources the plugin relies on and whether they are served
+	Resources []ResourceStatus `json:"resources"`
+	// Warnings lists the discovery requests that could not be completed
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type ResourceStatus struct {
+	Resource   string `json:"resource"`
+	Registered bool   `json:"registered"`
+}
+
+// Compatible returns true if all resources the plugin relies on are served
+func (s *ServerInfo) Compatible() bool {
+	for _, r := range s.Resources {
+		if !r.Registered {
+			return false
+		}
+	}
+	return true
+}
+
+// ServerInfo reports the sdcio API groups served by the cluster and whether
+// the resources the plugin relies on are registered. Discovery requests
+// denied by RBAC are reported as warnings instead of failing.
+func (c *ConfigClient) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	info := &ServerInfo{Groups: map[string][]string{}}
+	d := c.c.Discovery()
+
+	var serverVersion *version.Info
+	err := c.withRetry(ctx, func() (err error) {
+		serverVersion, err = d.ServerVersion()
+		return err
+	})
+	if err != nil {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("server version: %v", err))
+	} else {
+		info.KubernetesVersion = serverVersion.GitVersion
+	}
+
+	var groups *v1.APIGroupList
+	err = c.withRetry(ctx, func() (err error) {
+		groups, err = d.ServerGroups()
+		return err
+	})
+	switch {
+	case apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err):
+		info.Warnings = append(info.Warnings, fmt.Sprintf("server groups: %v", err))
+	case err != nil:
+		return nil, err
+	default:
+		for _, g := range groups.Groups {
+			if g.Name != configv1alpha1.SchemeGroupVersion.Group && g.Name != invv1alpha1.SchemeGroupVersion.Group {
+				continue
+			}
+			for _, v := range g.Versions {
+				info.Groups[g.Name] = append(info.Groups[g.Name], v.Version)
+			}
+		}
+	}
+
+	served := map[schema.GroupVersion][]string{}
+	for _, gvr := range expectedResources {
+		gv := gvr.GroupVersion()
+		resources, checked := served[gv]
+		if !checked {
+			var list *v1.APIResourceList
+			err := c.withRetry(ctx, func() (err error) {
+				list, err = d.ServerResourcesForGroupVersion(gv.String())
+				return err
+			})
+			switch {
+			case apierrors.IsNotFound(err):
+			case err != nil:
+				info.Warnings = append(info.Warnings, fmt.Sprintf("resources of %s: %v", gv, err))
+			default:
+				for _, r := range list.APIResources {
+					resources = append(resources, r.Name)
+				}
+			}
+			served[gv] = resources
+		}
+		info.Resources = append(info.Resources, ResourceStatus{
+			Resource:   gvr.GroupResource().String() + "/" + gvr.Version,
+			Registered: slices.Contains(resources, gvr.Resource),
+		})
+	}
+	return info, nil
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+)
+
+// Value types reported for blame tree leaves
+const (
+	ValueTypeString      = "string"
+	ValueTypeInt         = "int"
+	ValueTypeUint        = "uint"
+	ValueTypeBool        = "bool"
+	ValueTypeDecimal     = "decimal"
+	ValueTypeFloat       = "float"
+	ValueTypeDouble      = "double"
+	ValueTypeLeafList    = "leaflist"
+	ValueTypeIdentityRef = "identityref"
+	ValueTypeEmpty       = "empty"
+	ValueTypeBytes       = "bytes"
+	ValueTypeJSON        = "json"
+	ValueTypeAny         = "any"
+
+	// ValueTypeNumeric is not reported, but matches all numeric types in filters
+	ValueTypeNumeric = "numeric"
+)
+
+// ValueTypes lists the value types a BlameFilter can match on
+var ValueTypes = []string{
+	ValueTypeString, ValueTypeInt, ValueTypeUint, ValueTypeBool, ValueTypeDecimal,
+	ValueTypeFloat, ValueTypeDouble, ValueTypeLeafList, ValueTypeIdentityRef,
+	ValueTypeEmpty, ValueTypeBytes, ValueTypeJSON, ValueTypeAny, ValueTypeNumeric,
+}
+
+// ValueType returns the type of the typed value, or an empty string for nil
+func ValueType(tv *sdcpb.TypedValue) string {
+	switch tv.GetValue().(type) {
+	case *sdcpb.TypedValue_StringVal, *sdcpb.TypedValue_AsciiVal:
+		return ValueTypeString
+	case *sdcpb.TypedValue_IntVal:
+		return ValueTypeInt
+	case *sdcpb.TypedValue_UintVal:
+		return ValueTypeUint
+	case *sdcpb.TypedValue_BoolVal:
+		return ValueTypeBool
+	case *sdcpb.TypedValue_DecimalVal:
+		return ValueTypeDecimal
+	case *sdcpb.TypedValue_FloatVal:
+		return ValueTypeFloat
+	case *sdcpb.TypedValue_DoubleVal:
+		return ValueTypeDouble
+	case *sdcpb.TypedValue_LeaflistVal:
+		return ValueTypeLeafList
+	case *sdcpb.TypedValue_IdentityrefVal:
+		return ValueTypeIdentityRef
+	case *sdcpb.TypedValue_EmptyVal:
+		return ValueTypeEmpty
+	case *sdcpb.TypedValue_BytesVal, *sdcpb.TypedValue_ProtoBytes:
+		return ValueTypeBytes
+	case *sdcpb.TypedValue_JsonVal, *sdcpb.TypedValue_JsonIetfVal:
+		return ValueTypeJSON
+	case *sdcpb.TypedValue_AnyVal:
+		return ValueTypeAny
+	}
+	return ""
+}
+
+// matchesValueType returns true if tv is of type t, ValueTypeNumeric matching
+// all numeric types
+func matchesValueType(tv *sdcpb.TypedValue, t string) bool {
+	vt := ValueType(tv)
+	if t == ValueTypeNumeric {
+		switch vt {
+		case ValueTypeInt, ValueTypeUint, ValueTypeDecimal, ValueTypeFloat, ValueTypeDouble:
+			return true
+		}
+		return false
+	}
+	return vt != "" && vt == t
+}
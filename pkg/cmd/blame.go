@@ -17,19 +17,37 @@ limitations under the License.
 package cmd
 
 import (
-	"context"
+	"bytes"
 	"fmt"
+	"io"
+	"slices"
 
 	"github.com/spf13/cobra"
 
 	"github.com/sdcio/kubectl-sdcio/pkg/client"
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 )
 
 type BlameOptions struct {
-	namespace string
-	target    string
+	namespace   string
+	target      string
+	path        string
+	maxDepth    int
+	format      string
+	stats       bool
+	deviated    bool
+	matchMode   string
+	priority    int64
+	prioritySet bool
+	valueType   string
+	unmanaged   bool
+	owner       string
+	pathGlob    string
+	jsonPath    string
+	outputFile  string
+	gzip        bool
 	MyOptions
 }
 
@@ -43,8 +61,11 @@ func NewBlameOptions(streams genericiooptions.IOStreams) *BlameOptions {
 	}
 }
 
-func (o *BlameOptions) Complete(_ *cobra.Command, _ []string) error {
+func (o *BlameOptions) Complete(cmd *cobra.Command, _ []string) error {
 	var err error
+	if cmd != nil {
+		o.prioritySet = cmd.Flags().Changed("priority")
+	}
 	clientConfig := o.configFlags.ToRawKubeConfigLoader()
 
 	o.restConfig, err = o.configFlags.ToRESTConfig()
@@ -69,25 +90,99 @@ func (o *BlameOptions) Validate() error {
 	if o.namespace == "" {
 		return fmt.Errorf("namespace not set")
 	}
+	if o.retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	if err := o.filter().Validate(); err != nil {
+		return err
+	}
+	if !slices.Contains(blameFormats, o.format) {
+		return fmt.Errorf("unknown format %q, must be one of %v", o.format, blameFormats)
+	}
+	if o.jsonPath != "" {
+		if o.stats || o.format != formatTree {
+			return fmt.Errorf("--jsonpath can not be combined with --stats or --format")
+		}
+		if _, err := parseJSONPath(o.jsonPath); err != nil {
+			return err
+		}
+	}
+	if o.gzip && o.outputFile == "" {
+		return fmt.Errorf("--gzip requires --output")
+	}
+	if o.stats && !slices.Contains([]string{formatTree, formatJSON, formatYAML}, o.format) {
+		return fmt.Errorf("format %q is not supported with --stats", o.format)
+	}
 	return nil
 }
 
-func (o *BlameOptions) Run(_ *cobra.Command) error {
-	ctx := context.Background()
+// filter builds the BlameFilter from the command line options
+func (o *BlameOptions) filter() client.BlameFilter {
+	f := client.BlameFilter{
+		MatchMode: client.MatchMode(o.matchMode),
+		Path:      o.path,
+		MaxDepth:  o.maxDepth,
+		Deviation: o.deviated,
+		ValueType: o.valueType,
+		Unmanaged: o.unmanaged,
+		Owner:     o.owner,
+		PathGlob:  o.pathGlob,
+	}
+	if o.prioritySet {
+		f.Priority = &o.priority
+	}
+	return f
+}
+
+func (o *BlameOptions) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
 	cl, err := client.NewConfigClient(o.restConfig)
 	if err != nil {
 		return err
 	}
+	cl.SetRetries(o.retries)
 
-	bt, err := cl.GetBlameTree(ctx, o.namespace, o.target)
+	bt, truncated, err := cl.GetFilteredBlameTree(ctx, o.namespace, o.target, o.filter())
 	if err != nil {
 		return err
 	}
 
-	fmt.Println(bt.ToString())
+	if o.outputFile == "" {
+		c, err := newColorizer(cmd, o.Out)
+		if err != nil {
+			return err
+		}
+		if err := o.write(o.Out, bt, truncated, c); err != nil {
+			return err
+		}
+	} else {
+		buf := &bytes.Buffer{}
+		if err := o.write(buf, bt, truncated, colorizer{}); err != nil {
+			return err
+		}
+		if err := writeOutputFile(o.outputFile, buf.Bytes(), o.gzip); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		fmt.Fprintf(o.ErrOut, "Blame output written to %s\n", o.outputFile)
+	}
+
+	if o.filter().HasCriteria() && bt.ChildCount() == 0 {
+		return noMatchError("no nodes match the filter")
+	}
 	return nil
 }
 
+// write renders the blame tree as selected by the options to w
+func (o *BlameOptions) write(w io.Writer, bt *sdcpb.BlameTreeElement, truncated client.TruncatedNodes, c colorizer) error {
+	if o.jsonPath != "" {
+		return writeBlameJSONPath(w, bt, o.jsonPath)
+	}
+	if o.stats {
+		return writeBlameStats(w, client.BlameStatsByOwner(bt), o.format)
+	}
+	return writeBlameTree(w, bt, truncated, o.format, c)
+}
+
 // NewCmdBlame provides a cobra command wrapping BlameOptions
 func NewCmdBlame(streams genericiooptions.IOStreams) (*cobra.Command, error) {
 
@@ -103,7 +198,7 @@ func NewCmdBlame(streams genericiooptions.IOStreams) (*cobra.Command, error) {
 				return err
 			}
 			if err := o.Validate(); err != nil {
-				return err
+				return usageError(err)
 			}
 			if err := o.Run(c); err != nil {
 				return err
@@ -114,15 +209,42 @@ func NewCmdBlame(streams genericiooptions.IOStreams) (*cobra.Command, error) {
 	}
 
 	cmd.Flags().StringVar(&o.target, "target", "", "target to get the blame config for")
+	cmd.Flags().StringVar(&o.path, "path", "", "only show the subtree at the given path, e.g. /interface[name=ethernet-1/1]")
+	cmd.Flags().IntVar(&o.maxDepth, "max-depth", 0, "collapse branches deeper than the given depth, marked '… (N children hidden)' in the tree format (0 means unlimited)")
+	cmd.Flags().StringVar(&o.format, "format", formatTree, fmt.Sprintf("output format, one of %v", blameFormats))
+	cmd.Flags().StringVar(&o.outputFile, "output", "", "write the output to the given file instead of stdout")
+	cmd.Flags().BoolVar(&o.gzip, "gzip", false, "gzip compress the file written with --output, implied by a .gz extension")
+	cmd.Flags().StringVar(&o.jsonPath, "jsonpath", "", "jsonpath expression applied to the JSON serialized blame tree, e.g. '{.childs[*].name}'")
+	cmd.Flags().BoolVar(&o.stats, "stats", false, "print the number of leaves and deviations per owner instead of the tree")
+	cmd.Flags().BoolVar(&o.deviated, "deviated", false, "only show nodes that deviate from the intended value")
+	cmd.Flags().BoolVar(&o.unmanaged, "unmanaged", false, "only show leaves that are not owned by any intent")
+	cmd.Flags().Int64Var(&o.priority, "priority", 0, "only show nodes owned by configs of the given priority")
+	cmd.Flags().StringVar(&o.owner, "owner", "", "only show nodes owned by the given owner, e.g. default.intent1 or running")
+	cmd.Flags().StringVar(&o.pathGlob, "path-glob", "", "only show nodes at or below the blame paths matching the glob, * matching any characters, e.g. '/interface/ethernet-1/*'")
+	cmd.Flags().StringVar(&o.valueType, "value-type", "", fmt.Sprintf("only show leaves with a value of the given type, one of %v", client.ValueTypes))
+	cmd.Flags().IntVar(&o.retries, "retries", client.DefaultRetries, "number of times transient API errors are retried")
+	cmd.Flags().StringVar(&o.matchMode, "match", string(client.MatchAll), "how filter criteria are combined, one of [all any]")
 	err := cmd.MarkFlagRequired("target")
 	if err != nil {
 		return nil, err
 	}
 
-	if err := cmd.RegisterFlagCompletionFunc("target", targetCompletionFunc(o)); err != nil {
+	if err := cmd.RegisterFlagCompletionFunc("target", targetCompletionFunc(&o.MyOptions)); err != nil {
+		return nil, err
+	}
+	if err := cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(blameFormats, cobra.ShellCompDirectiveNoFileComp)); err != nil {
+		return nil, err
+	}
+	if err := cmd.RegisterFlagCompletionFunc("value-type", cobra.FixedCompletions(client.ValueTypes, cobra.ShellCompDirectiveNoFileComp)); err != nil {
+		return nil, err
+	}
+	if err := cmd.RegisterFlagCompletionFunc("match", cobra.FixedCompletions([]string{string(client.MatchAll), string(client.MatchAny)}, cobra.ShellCompDirectiveNoFileComp)); err != nil {
 		return nil, err
 	}
 	o.configFlags.AddFlags(cmd.Flags())
+	if err := registerKubeconfigCompletions(cmd, o.configFlags); err != nil {
+		return nil, err
+	}
 
 	return cmd, nil
 }
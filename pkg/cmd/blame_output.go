@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sdcio/kubectl-sdcio/pkg/client"
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	formatTree = "tree"
+	formatJSON = "json"
+	formatYAML = "yaml"
+	formatDot  = "dot"
+	formatFlat = "flat"
+	formatCSV  = "csv"
+	formatTSV  = "tsv"
+)
+
+var blameFormats = []string{formatTree, formatJSON, formatYAML, formatDot, formatFlat, formatCSV, formatTSV}
+
+// writeBlameTree serializes the blame tree in the requested format to w,
+// deviations of the tree and flat formats are colored by c. The nodes cut
+// off by --max-depth are only marked in the tree format, the other formats
+// are data and hold the nodes of the device only.
+func writeBlameTree(w io.Writer, bt *sdcpb.BlameTreeElement, truncated client.TruncatedNodes, format string, c colorizer) error {
+	switch format {
+	case formatTree:
+		counts := client.CountBlameNodes(bt)
+		summary := fmt.Sprintf("%d deviated (%.1f%%)", counts.Deviated, counts.DeviatedPercent())
+		if counts.Deviated > 0 {
+			summary = c.red(summary)
+		}
+		if len(truncated) > 0 {
+			summary += fmt.Sprintf(", %d truncated by --max-depth", len(truncated))
+		}
+		tree := withTruncationMarkers(bt, truncated).ToString()
+		_, err := fmt.Fprintf(w, "%s\n%d nodes, %s\n", colorBlameDeviations(tree, c), counts.Total, summary)
+		return err
+	case formatJSON:
+		b, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(bt)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case formatYAML:
+		b, err := protojson.Marshal(bt)
+		if err != nil {
+			return err
+		}
+		y, err := yaml.JSONToYAML(b)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(y)
+		return err
+	case formatDot:
+		return writeBlameDot(w, bt)
+	case formatFlat:
+		return writeBlameFlat(w, client.FlattenBlameTree(bt), c)
+	case formatCSV:
+		return writeBlameCSV(w, client.FlattenBlameTree(bt), ',')
+	case formatTSV:
+		return writeBlameCSV(w, client.FlattenBlameTree(bt), '\t')
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// withTruncationMarkers returns bt with a "… (N children hidden)" child added
+// to every truncated node, for rendering only. The tree is copied, bt itself
+// is not modified.
+func withTruncationMarkers(bt *sdcpb.BlameTreeElement, truncated client.TruncatedNodes) *sdcpb.BlameTreeElement {
+	if len(truncated) == 0 {
+		return bt
+	}
+	result := &sdcpb.BlameTreeElement{
+		Name:           bt.GetName(),
+		Owner:          bt.GetOwner(),
+		Value:          bt.GetValue(),
+		DeviationValue: bt.GetDeviationValue(),
+		Childs:         make([]*sdcpb.BlameTreeElement, 0, bt.ChildCount()+1),
+	}
+	for _, child := range bt.GetChilds() {
+		result.Childs = append(result.Childs, withTruncationMarkers(child, truncated))
+	}
+	if hidden := truncated[bt]; hidden > 0 {
+		children := "children"
+		if hidden == 1 {
+			children = "child"
+		}
+		result.Childs = append(result.Childs, &sdcpb.BlameTreeElement{Name: fmt.Sprintf("… (%d %s hidden)", hidden, children)})
+	}
+	return result
+}
+
+// colorBlameDeviations colors the lines of the rendered tree that show a deviation
+func colorBlameDeviations(tree string, c colorizer) string {
+	if !c.enabled {
+		return tree
+	}
+	lines := strings.Split(tree, "\n")
+	for i, line := range lines {
+		if strings.Contains(line, " [~> ") {
+			lines[i] = c.red(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeBlameFlat writes one "path value owner" line per leaf
+func writeBlameFlat(w io.Writer, leaves []client.BlameLeaf, c colorizer) error {
+	for _, l := range leaves {
+		line := fmt.Sprintf("%s %s %s", l.Path, l.Value, l.Owner)
+		if l.DeviationValue != "" {
+			line += c.red(" ~> " + l.DeviationValue)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBlameCSV writes the leaves as delimiter separated records with a header
+func writeBlameCSV(w io.Writer, leaves []client.BlameLeaf, delimiter rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = delimiter
+	if err := cw.Write([]string{"path", "value", "owner", "type", "deviation"}); err != nil {
+		return err
+	}
+	for _, l := range leaves {
+		if err := cw.Write([]string{l.Path, l.Value, l.Owner, l.Type, l.DeviationValue}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeBlameDot renders the blame tree as a Graphviz digraph. Nodes are
+// labeled with their name, owner and value, deviated nodes are colored red.
+func writeBlameDot(w io.Writer, bt *sdcpb.BlameTreeElement) error {
+	sb := &strings.Builder{}
+	sb.WriteString("digraph blame {\n")
+	sb.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	id := 0
+	writeBlameDotNode(sb, bt, &id)
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeBlameDotNode writes bte and its children, returning the id of the node of bte
+func writeBlameDotNode(sb *strings.Builder, bte *sdcpb.BlameTreeElement, id *int) int {
+	nodeID := *id
+	*id++
+
+	label := dotEscape(bte.GetName()) + "\\n" + dotEscape(bte.OwnerNormalized())
+	if bte.GetValue() != nil {
+		label += "\\n" + dotEscape(bte.GetValue().ToString())
+	}
+	attrs := ""
+	if bte.IsDeviated() {
+		label += "\\n~> " + dotEscape(bte.GetDeviationValue().ToString())
+		attrs = ", color=red, fontcolor=red"
+	}
+	fmt.Fprintf(sb, "  n%d [label=\"%s\"%s];\n", nodeID, label, attrs)
+
+	for c := range bte.SortedChildIterator() {
+		childID := writeBlameDotNode(sb, c, id)
+		fmt.Fprintf(sb, "  n%d -> n%d;\n", nodeID, childID)
+	}
+	return nodeID
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func dotEscape(s string) string {
+	return dotEscaper.Replace(s)
+}
+
+// writeBlameStats writes the per owner statistics as a table or serialized in the requested format to w
+func writeBlameStats(w io.Writer, stats []client.OwnerStats, format string) error {
+	switch format {
+	case formatTree:
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(tw, "OWNER\tLEAVES\tDEVIATED")
+		for _, s := range stats {
+			owner := s.Owner
+			if owner == "" {
+				owner = "-----"
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%d\n", owner, s.Leaves, s.Deviated)
+		}
+		return tw.Flush()
+	case formatJSON:
+		b, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case formatYAML:
+		b, err := yaml.Marshal(stats)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// parseJSONPath parses a kubectl style jsonpath expression, the surrounding
+// braces are optional
+func parseJSONPath(expr string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+	jp := jsonpath.New("blame")
+	if err := jp.Parse(expr); err != nil {
+		return nil, fmt.Errorf("invalid jsonpath expression: %w", err)
+	}
+	return jp, nil
+}
+
+// writeBlameJSONPath evaluates the jsonpath expression against the protojson
+// serialization of the blame tree
+func writeBlameJSONPath(w io.Writer, bt *sdcpb.BlameTreeElement, expr string) error {
+	jp, err := parseJSONPath(expr)
+	if err != nil {
+		return err
+	}
+	b, err := protojson.Marshal(bt)
+	if err != nil {
+		return err
+	}
+	var data any
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	if err := jp.Execute(w, data); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
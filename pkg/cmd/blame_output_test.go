@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sdcio/kubectl-sdcio/pkg/client"
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+)
+
+func TestWriteBlameTreeTruncation(t *testing.T) {
+	entry := &sdcpb.BlameTreeElement{Name: "ethernet-1/1"}
+	bt := &sdcpb.BlameTreeElement{
+		Name: "root",
+		Childs: []*sdcpb.BlameTreeElement{
+			{Name: "interface", Childs: []*sdcpb.BlameTreeElement{entry}},
+		},
+	}
+	truncated := client.TruncatedNodes{entry: 2}
+
+	for _, format := range blameFormats {
+		t.Run(format, func(t *testing.T) {
+			out := &strings.Builder{}
+			if err := writeBlameTree(out, bt, truncated, format, colorizer{}); err != nil {
+				t.Fatal(err)
+			}
+			marked := strings.Contains(out.String(), "children hidden")
+			if marked != (format == formatTree) {
+				t.Errorf("truncation marker written %v, want %v:\n%s", marked, format == formatTree, out)
+			}
+			if format == formatTree && !strings.Contains(out.String(), "2 nodes, 0 deviated (0.0%), 1 truncated by --max-depth") {
+				t.Errorf("summary does not count the truncated node:\n%s", out)
+			}
+		})
+	}
+	if entry.ChildCount() != 0 {
+		t.Errorf("rendering added %d children to the tree", entry.ChildCount())
+	}
+}
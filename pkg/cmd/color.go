@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// color modes of the --color flag
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+var ColorModes = []string{ColorAuto, ColorAlways, ColorNever}
+
+const colorFlag = "color"
+
+// ANSI color codes
+const (
+	colorRed    = "31"
+	colorGreen  = "32"
+	colorYellow = "33"
+)
+
+// AddColorFlag adds the --color flag to the persistent flags of the root command
+func AddColorFlag(root *cobra.Command) error {
+	root.PersistentFlags().String(colorFlag, ColorAuto, fmt.Sprintf("colorize the output, one of %v. auto colors terminals unless NO_COLOR is set", ColorModes))
+	return root.RegisterFlagCompletionFunc(colorFlag, cobra.FixedCompletions(ColorModes, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// colorizer wraps text in ANSI colors if enabled, the zero value is disabled
+type colorizer struct {
+	enabled bool
+}
+
+// newColorizer returns a colorizer for writing to out according to the
+// --color flag of cmd. A nil out, e.g. when writing to a file, disables colors.
+func newColorizer(cmd *cobra.Command, out io.Writer) (colorizer, error) {
+	mode := ColorAuto
+	if f := cmd.Flags().Lookup(colorFlag); f != nil {
+		mode = f.Value.String()
+	}
+	if !slices.Contains(ColorModes, mode) {
+		return colorizer{}, usageError(fmt.Errorf("unknown color mode %q, must be one of %v", mode, ColorModes))
+	}
+	if out == nil || mode == ColorNever {
+		return colorizer{}, nil
+	}
+	if mode == ColorAlways {
+		return colorizer{enabled: true}, nil
+	}
+	// see https://no-color.org
+	if os.Getenv("NO_COLOR") != "" {
+		return colorizer{}, nil
+	}
+	f, isFile := out.(*os.File)
+	return colorizer{enabled: isFile && term.IsTerminal(int(f.Fd()))}, nil
+}
+
+func (c colorizer) color(code string, s string) string {
+	if !c.enabled || s == "" {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+func (c colorizer) red(s string) string    { return c.color(colorRed, s) }
+func (c colorizer) green(s string) string  { return c.color(colorGreen, s) }
+func (c colorizer) yellow(s string) string { return c.color(colorYellow, s) }
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+type ConvertOptions struct {
+	from   string
+	to     string
+	input  string
+	output string
+	gzip   bool
+	genericiooptions.IOStreams
+}
+
+// NewConvertOptions provides an instance of ConvertOptions with default values
+func NewConvertOptions(streams genericiooptions.IOStreams) *ConvertOptions {
+	return &ConvertOptions{
+		IOStreams: streams,
+	}
+}
+
+func (o *ConvertOptions) Complete(_ *cobra.Command, _ []string) error {
+	var err error
+	if o.from == "" && o.input != "" && o.input != "-" {
+		if o.from, err = documentFormatFromFile(o.input); err != nil {
+			return usageError(fmt.Errorf("%w, use --from", err))
+		}
+	}
+	if o.to == "" && o.output != "" {
+		if o.to, err = documentFormatFromFile(strings.TrimSuffix(o.output, gzipExtension)); err != nil {
+			return usageError(fmt.Errorf("%w, use --to", err))
+		}
+	}
+	return nil
+}
+
+// Validate validates the options
+func (o *ConvertOptions) Validate() error {
+	if o.from == "" {
+		return fmt.Errorf("--from is required when reading from stdin")
+	}
+	if o.to == "" {
+		return fmt.Errorf("--to is required when writing to stdout")
+	}
+	if !slices.Contains(documentFormats, o.from) {
+		return fmt.Errorf("unknown format %q, must be one of %v", o.from, documentFormats)
+	}
+	if !slices.Contains(documentFormats, o.to) {
+		return fmt.Errorf("unknown format %q, must be one of %v", o.to, documentFormats)
+	}
+	if o.gzip && o.output == "" {
+		return fmt.Errorf("--gzip requires --output")
+	}
+	return nil
+}
+
+func (o *ConvertOptions) Run() error {
+	var data []byte
+	var err error
+	if o.input == "" || o.input == "-" {
+		data, err = io.ReadAll(o.In)
+	} else {
+		data, err = os.ReadFile(o.input)
+	}
+	if err != nil {
+		return err
+	}
+
+	doc, err := decodeDocument(data, o.from)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s input: %w", o.from, err)
+	}
+	out, err := encodeDocument(doc, o.to)
+	if err != nil {
+		return err
+	}
+
+	if o.output == "" {
+		_, err = o.Out.Write(out)
+		return err
+	}
+	return writeOutputFile(o.output, out, o.gzip)
+}
+
+// NewCmdConvert provides a cobra command wrapping ConvertOptions
+func NewCmdConvert(streams genericiooptions.IOStreams) (*cobra.Command, error) {
+
+	o := NewConvertOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "convert",
+		Short: "convert a config document between json, yaml and xml",
+		Long: `Convert a config document between json, yaml and xml.
+
+The conversion is structural, no schema is involved: xml attributes and
+namespaces are dropped, repeated xml elements become lists and elements
+are written in name order. Characters not allowed in xml element names
+are written as _xHHHH_, their code point in hex, and read back when
+converting from xml, e.g. ethernet-1/1 becomes ethernet-1_x002F_1.`,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return usageError(err)
+			}
+			if err := o.Run(); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&o.from, "from", "", fmt.Sprintf("input format, one of %v, derived from the --input extension if not set", documentFormats))
+	cmd.Flags().StringVar(&o.to, "to", "", fmt.Sprintf("output format, one of %v, derived from the --output extension if not set", documentFormats))
+	cmd.Flags().StringVar(&o.input, "input", "", "file to read the document from, stdin if not set")
+	cmd.Flags().StringVar(&o.output, "output", "", "file to write the converted document to, stdout if not set")
+	cmd.Flags().BoolVar(&o.gzip, "gzip", false, "gzip compress the file written with --output, implied by a .gz extension")
+	for _, f := range []string{"from", "to"} {
+		if err := cmd.RegisterFlagCompletionFunc(f, cobra.FixedCompletions(documentFormats, cobra.ShellCompDirectiveNoFileComp)); err != nil {
+			return nil, err
+		}
+	}
+
+	return cmd, nil
+}
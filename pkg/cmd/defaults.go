@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ConfigFileEnv overrides the location of the config file
+	ConfigFileEnv = "SDCIO_CONFIG"
+	// envPrefix is the prefix of the environment variables providing flag defaults
+	envPrefix = "SDCIO_"
+)
+
+// globalFlags are the flags that take their default from the environment or
+// the config file. They mean the same for every command, unlike e.g. --format
+// or --priority, which would silently change the result of the commands
+// sharing the name.
+var globalFlags = []string{"namespace", "kubeconfig", "context", "retries", colorFlag, "error-format"}
+
+// DefaultConfigFile returns the location of the config file, ~/.sdcio/config.yaml
+// unless overridden by SDCIO_CONFIG
+func DefaultConfigFile() string {
+	if p := os.Getenv(ConfigFileEnv); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".sdcio", "config.yaml")
+}
+
+// LoadConfigFile reads the flag defaults from the config file, a yaml map of
+// global flag names to values, e.g. "namespace: network". A missing file is
+// not an error.
+func LoadConfigFile(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]any{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	result := make(map[string]string, len(values))
+	for name, v := range values {
+		switch v.(type) {
+		case map[string]any, []any:
+			return nil, fmt.Errorf("invalid config file %s: value of %q must be a scalar", path, name)
+		}
+		result[name] = fmt.Sprint(v)
+	}
+	return result, nil
+}
+
+// envName returns the environment variable providing the default of the flag,
+// e.g. SDCIO_ERROR_FORMAT for --error-format
+func envName(flag string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// ApplyDefaults sets the global flags not given on the command line from the
+// environment or, failing that, from the config file values. The resulting
+// precedence is flag > env > config file > built-in default. Other flags keep
+// their built-in default.
+func ApplyDefaults(flags *pflag.FlagSet, fileValues map[string]string, getenv func(string) string) error {
+	var errs []error
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Changed || !slices.Contains(globalFlags, f.Name) {
+			return
+		}
+
+		source := envName(f.Name)
+		value := getenv(source)
+		if value == "" {
+			v, exists := fileValues[f.Name]
+			if !exists {
+				return
+			}
+			source, value = "config file", v
+		}
+		// set through the flag set, so the flag counts as given for
+		// required flags and Changed checks
+		if err := flags.Set(f.Name, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid default for --%s from %s: %w", f.Name, source, err))
+		}
+	})
+	if len(errs) > 0 {
+		return usageError(errors.Join(errs...))
+	}
+	return nil
+}
+
+// PrepareFlags applies the defaults of the config file and the environment to
+// the flags of cmd and checks its required flags and flag groups. It is meant
+// to run as the persistent pre run of the root command: cobra checks the
+// required flags itself after that, so they can be set by the defaults, but
+// reports them without an exit code.
+func PrepareFlags(cmd *cobra.Command, configFile string, getenv func(string) string) error {
+	fileValues, err := LoadConfigFile(configFile)
+	if err != nil {
+		return err
+	}
+	if err := ApplyDefaults(cmd.Flags(), fileValues, getenv); err != nil {
+		return err
+	}
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return usageError(err)
+	}
+	if err := cmd.ValidateFlagGroups(); err != nil {
+		return usageError(err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// writeConfigFile writes content to a config file in a temporary directory
+// and returns its path
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestFlags() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("namespace", "", "")
+	flags.String("context", "", "")
+	flags.Int("retries", 3, "")
+	flags.String("format", "tree", "")
+	flags.Int64("priority", 0, "")
+	return flags
+}
+
+func fakeGetenv(env map[string]string) func(string) string {
+	return func(name string) string { return env[name] }
+}
+
+func TestApplyDefaults(t *testing.T) {
+	file := writeConfigFile(t, `
+namespace: from-file
+retries: 2
+format: json
+`)
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want map[string]string
+	}{
+		{
+			name: "config file",
+			want: map[string]string{"namespace": "from-file", "retries": "2", "context": ""},
+		},
+		{
+			name: "env over config file",
+			env:  map[string]string{"SDCIO_NAMESPACE": "from-env", "SDCIO_CONTEXT": "lab"},
+			want: map[string]string{"namespace": "from-env", "retries": "2", "context": "lab"},
+		},
+		{
+			name: "flag over env and config file",
+			args: []string{"--namespace", "from-flag", "--retries=5"},
+			env:  map[string]string{"SDCIO_NAMESPACE": "from-env", "SDCIO_RETRIES": "3"},
+			want: map[string]string{"namespace": "from-flag", "retries": "5"},
+		},
+		{
+			name: "explicit flag set to the built-in default",
+			args: []string{"--retries=3"},
+			want: map[string]string{"retries": "3"},
+		},
+		{
+			name: "command specific flags keep their built-in default",
+			env:  map[string]string{"SDCIO_FORMAT": "yaml", "SDCIO_PRIORITY": "10"},
+			want: map[string]string{"format": "tree", "priority": "0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileValues, err := LoadConfigFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			flags := newTestFlags()
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := ApplyDefaults(flags, fileValues, fakeGetenv(tt.env)); err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.want {
+				if got := flags.Lookup(name).Value.String(); got != want {
+					t.Errorf("--%s = %q, want %q", name, got, want)
+				}
+			}
+			for _, name := range []string{"format", "priority"} {
+				if flags.Changed(name) {
+					t.Errorf("--%s marked as given", name)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyDefaultsInvalidValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		fileValues map[string]string
+		env        map[string]string
+	}{
+		{name: "env", env: map[string]string{"SDCIO_RETRIES": "many"}},
+		{name: "config file", fileValues: map[string]string{"retries": "many"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ApplyDefaults(newTestFlags(), tt.fileValues, fakeGetenv(tt.env))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if code := ExitCode(err); code != ExitUsage {
+				t.Errorf("exit code %d, want %d", code, ExitUsage)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		values, err := LoadConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))
+		if err != nil || values != nil {
+			t.Errorf("got %v, %v, want no values and no error", values, err)
+		}
+	})
+	t.Run("no file", func(t *testing.T) {
+		values, err := LoadConfigFile("")
+		if err != nil || values != nil {
+			t.Errorf("got %v, %v, want no values and no error", values, err)
+		}
+	})
+	t.Run("malformed file", func(t *testing.T) {
+		if _, err := LoadConfigFile(writeConfigFile(t, "namespace: [unclosed\n")); err == nil {
+			t.Error("expected an error")
+		}
+	})
+	t.Run("not a map", func(t *testing.T) {
+		if _, err := LoadConfigFile(writeConfigFile(t, "- namespace\n")); err == nil {
+			t.Error("expected an error")
+		}
+	})
+	t.Run("non scalar value", func(t *testing.T) {
+		if _, err := LoadConfigFile(writeConfigFile(t, "namespace:\n  name: x\n")); err == nil {
+			t.Error("expected an error")
+		}
+	})
+	t.Run("unknown key", func(t *testing.T) {
+		// the file is shared by all commands, a key that is not a flag of
+		// the running command is ignored
+		values, err := LoadConfigFile(writeConfigFile(t, "namespace: network\nno-such-flag: x\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		flags := newTestFlags()
+		if err := ApplyDefaults(flags, values, fakeGetenv(nil)); err != nil {
+			t.Fatalf("unknown key not ignored: %v", err)
+		}
+		if got := flags.Lookup("namespace").Value.String(); got != "network" {
+			t.Errorf("--namespace = %q, want network", got)
+		}
+	})
+}
+
+func TestPrepareFlagsConfigFile(t *testing.T) {
+	file := writeConfigFile(t, "retries: [1, 2]\n")
+	root := newTestRoot(t, nil)
+	root.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		return PrepareFlags(cmd, file, fakeGetenv(nil))
+	}
+	root.SetArgs([]string{"convert"})
+	if err := root.Execute(); err == nil {
+		t.Error("expected an error for the invalid config file")
+	}
+}
+
+func TestPrepareFlagsBlameIgnoresCommandFlagEnv(t *testing.T) {
+	// SDCIO_PRIORITY must not turn on the priority filter of blame, nor
+	// SDCIO_FORMAT and SDCIO_OUTPUT change where and how it writes
+	root := newTestRoot(t, map[string]string{
+		"SDCIO_PRIORITY": "10",
+		"SDCIO_FORMAT":   formatJSON,
+		"SDCIO_OUTPUT":   "blame.json",
+	})
+	blame, _, err := root.Find([]string{"blame"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.SetArgs([]string{"blame", "--target", "dev", "--kubeconfig", filepath.Join(t.TempDir(), "missing")})
+	// without a cluster the command fails after the flags are prepared
+	_ = root.Execute()
+
+	for _, name := range []string{"priority", "format", "output"} {
+		if blame.Flags().Changed(name) {
+			t.Errorf("--%s set from the environment", name)
+		}
+	}
+	if got := blame.Flags().Lookup("format").Value.String(); got != formatTree {
+		t.Errorf("--format = %q, want %q", got, formatTree)
+	}
+}
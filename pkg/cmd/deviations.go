@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sdcio/kubectl-sdcio/pkg/client"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"sigs.k8s.io/yaml"
+)
+
+type DeviationsOptions struct {
+	namespace   string
+	concurrency int
+	format      string
+	MyOptions
+}
+
+// NewDeviationsOptions provides an instance of DeviationsOptions with default values
+func NewDeviationsOptions(streams genericiooptions.IOStreams) *DeviationsOptions {
+	return &DeviationsOptions{
+		MyOptions: MyOptions{
+			configFlags: genericclioptions.NewConfigFlags(true),
+			IOStreams:   streams,
+		},
+	}
+}
+
+func (o *DeviationsOptions) Complete(_ *cobra.Command, _ []string) error {
+	var err error
+	o.restConfig, err = o.configFlags.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.namespace, _, err = o.configFlags.ToRawKubeConfigLoader().Namespace()
+	return err
+}
+
+// Validate validates the options
+func (o *DeviationsOptions) Validate() error {
+	if o.namespace == "" {
+		return fmt.Errorf("namespace not set")
+	}
+	if o.concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
+	if o.retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	if !slices.Contains(statusFormats, o.format) {
+		return fmt.Errorf("unknown format %q, must be one of %v", o.format, statusFormats)
+	}
+	return nil
+}
+
+func (o *DeviationsOptions) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	cl, err := client.NewConfigClient(o.restConfig)
+	if err != nil {
+		return err
+	}
+	cl.SetRetries(o.retries)
+
+	// the devices that failed are left out of the report, their errors are
+	// returned once the report of the others is written
+	report, deviceErrs := cl.GetAllDeviations(ctx, o.namespace, o.concurrency)
+	if report == nil {
+		return deviceErrs
+	}
+	if err := writeDeviations(o.Out, o.format, report); err != nil {
+		return err
+	}
+	if deviceErrs != nil {
+		return deviceErrs
+	}
+
+	for _, devs := range report {
+		if len(devs) > 0 {
+			return nil
+		}
+	}
+	return noMatchError("no deviations found")
+}
+
+// writeDeviations writes the deviations report in the format, the table
+// listing the devices in name order
+func writeDeviations(w io.Writer, format string, report map[string][]client.BlameDeviation) error {
+	switch format {
+	case formatJSON:
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case formatYAML:
+		b, err := yaml.Marshal(report)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+
+	devices := make([]string, 0, len(report))
+	for device := range report {
+		devices = append(devices, device)
+	}
+	slices.Sort(devices)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tPATH\tOWNER\tVALUE\tDEVIATION")
+	for _, device := range devices {
+		for _, d := range report[device] {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", device, d.Path, d.Owner, d.Value, d.DeviationValue)
+		}
+	}
+	return tw.Flush()
+}
+
+// NewCmdDeviations provides a cobra command wrapping DeviationsOptions
+func NewCmdDeviations(streams genericiooptions.IOStreams) (*cobra.Command, error) {
+
+	o := NewDeviationsOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "deviations",
+		Short: "list the deviated paths of all targets in the namespace",
+		Long: `List the paths whose running value deviates from the intended value, for
+all targets in the namespace. The blame trees of up to --concurrency targets
+are fetched in parallel.
+
+A target whose blame tree cannot be fetched does not abort the report: the
+deviations of the other targets are written and the failures are reported
+afterwards, failing the command.`,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return usageError(err)
+			}
+			if err := o.Run(c); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&o.concurrency, "concurrency", client.DefaultConcurrency, "number of targets fetched in parallel")
+	cmd.Flags().StringVar(&o.format, "format", formatTable, fmt.Sprintf("output format, one of %v", statusFormats))
+	cmd.Flags().IntVar(&o.retries, "retries", client.DefaultRetries, "number of times transient API errors are retried")
+	if err := cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(statusFormats, cobra.ShellCompDirectiveNoFileComp)); err != nil {
+		return nil, err
+	}
+	o.configFlags.AddFlags(cmd.Flags())
+	if err := registerKubeconfigCompletions(cmd, o.configFlags); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sdcio/kubectl-sdcio/pkg/client"
+)
+
+func TestWriteDeviations(t *testing.T) {
+	report := map[string][]client.BlameDeviation{
+		"srl2": {{Path: "/system/name/host-name", Owner: "default.system", Value: "srl2", DeviationValue: "leaf2"}},
+		"srl1": {
+			{Path: "/interface[name=ethernet-1/1]/mtu", Owner: "default.intent", Value: "9000", DeviationValue: "1500"},
+			{Path: "/interface[name=ethernet-1/1]/admin-state", Owner: "default.intent", Value: "enable", DeviationValue: "disable"},
+		},
+		"srl3": {},
+	}
+
+	var table bytes.Buffer
+	if err := writeDeviations(&table, formatTable, report); err != nil {
+		t.Fatal(err)
+	}
+	wantTable := `TARGET   PATH                                        OWNER            VALUE    DEVIATION
+srl1     /interface[name=ethernet-1/1]/mtu           default.intent   9000     1500
+srl1     /interface[name=ethernet-1/1]/admin-state   default.intent   enable   disable
+srl2     /system/name/host-name                      default.system   srl2     leaf2
+`
+	if table.String() != wantTable {
+		t.Errorf("got table\n%s\nwant\n%s", table.String(), wantTable)
+	}
+
+	var out bytes.Buffer
+	if err := writeDeviations(&out, formatJSON, report); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string][]client.BlameDeviation{}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json %q: %v", out.String(), err)
+	}
+	if len(got) != 3 || len(got["srl1"]) != 2 || got["srl2"][0] != report["srl2"][0] || got["srl3"] == nil {
+		t.Errorf("got json report %v, want %v", got, report)
+	}
+}
+
+func TestDeviationsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		o       DeviationsOptions
+		wantErr bool
+	}{
+		{name: "defaults", o: DeviationsOptions{namespace: "default", concurrency: client.DefaultConcurrency, format: formatTable}},
+		{name: "no namespace", o: DeviationsOptions{concurrency: 1, format: formatTable}, wantErr: true},
+		{name: "zero concurrency", o: DeviationsOptions{namespace: "default", format: formatTable}, wantErr: true},
+		{name: "negative retries", o: DeviationsOptions{namespace: "default", concurrency: 1, format: formatJSON, MyOptions: MyOptions{retries: -1}}, wantErr: true},
+		{name: "unknown format", o: DeviationsOptions{namespace: "default", concurrency: 1, format: "xml"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.o.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
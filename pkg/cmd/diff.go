@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+const formatText = "text"
+
+var diffFormats = []string{formatText, formatJSON}
+
+type DiffOptions struct {
+	from   string
+	format string
+	files  []string
+	// formats holds the format of each file
+	formats []string
+	genericiooptions.IOStreams
+}
+
+// NewDiffOptions provides an instance of DiffOptions with default values
+func NewDiffOptions(streams genericiooptions.IOStreams) *DiffOptions {
+	return &DiffOptions{
+		IOStreams: streams,
+	}
+}
+
+func (o *DiffOptions) Complete(_ *cobra.Command, args []string) error {
+	o.files = args
+	o.formats = make([]string, len(args))
+	for i, file := range args {
+		o.formats[i] = o.from
+		if o.from != "" {
+			continue
+		}
+		format, err := documentFormatFromFile(file)
+		if err != nil {
+			return usageError(fmt.Errorf("%w, use --from", err))
+		}
+		o.formats[i] = format
+	}
+	return nil
+}
+
+// Validate validates the options
+func (o *DiffOptions) Validate() error {
+	if len(o.files) != 2 {
+		return fmt.Errorf("expected two files to compare, got %d", len(o.files))
+	}
+	if o.from != "" && !slices.Contains(documentFormats, o.from) {
+		return fmt.Errorf("unknown format %q, must be one of %v", o.from, documentFormats)
+	}
+	if !slices.Contains(diffFormats, o.format) {
+		return fmt.Errorf("unknown format %q, must be one of %v", o.format, diffFormats)
+	}
+	return nil
+}
+
+func (o *DiffOptions) Run(cmd *cobra.Command) error {
+	docs := make([]any, 0, len(o.files))
+	for i, file := range o.files {
+		doc, err := readDocument(file, o.formats[i])
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+
+	diff := diffDocuments(docs[0], docs[1])
+
+	if o.format == formatJSON {
+		b, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(o.Out, string(b)); err != nil {
+			return err
+		}
+		if !diff.isEmpty() {
+			return checkFailedError("documents differ")
+		}
+		return nil
+	}
+
+	c, err := newColorizer(cmd, o.Out)
+	if err != nil {
+		return err
+	}
+	if err := writeDiffText(o.Out, c, o.files, diff); err != nil {
+		return err
+	}
+	if diff.isEmpty() {
+		return nil
+	}
+	return checkFailedError("documents differ")
+}
+
+// writeDiffText writes the diff of the two files as one line per leaf
+func writeDiffText(w io.Writer, c colorizer, files []string, diff *documentDiff) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", files[0], files[1])
+	for _, e := range diff.Removed {
+		fmt.Fprintln(&sb, c.red(fmt.Sprintf("- %s: %s", e.Path, e.A)))
+	}
+	for _, e := range diff.Added {
+		fmt.Fprintln(&sb, c.green(fmt.Sprintf("+ %s: %s", e.Path, e.B)))
+	}
+	for _, e := range diff.Changed {
+		fmt.Fprintln(&sb, c.yellow(fmt.Sprintf("~ %s: %s -> %s", e.Path, e.A, e.B)))
+	}
+	if diff.isEmpty() {
+		fmt.Fprintln(&sb, "documents are identical")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// readDocument reads and decodes the file
+func readDocument(file string, format string) (any, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeDocument(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return doc, nil
+}
+
+// NewCmdDiff provides a cobra command wrapping DiffOptions
+func NewCmdDiff(streams genericiooptions.IOStreams) (*cobra.Command, error) {
+
+	o := NewDiffOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "diff FILE_A FILE_B",
+		Short: "compare two config documents leaf by leaf",
+		Long: `Compare two config documents leaf by leaf and report the added,
+removed and changed leaves by path. The documents may be json, yaml or xml
+and do not need to share a format.
+
+Lists written as objects keyed by their key values, like the output of get,
+are matched by key. Lists written as arrays are compared by position: the
+documents carry no schema telling which member is the key, so reordered
+entries are reported as changed.`,
+		Args:         UsageArgs(cobra.ExactArgs(2)),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return usageError(err)
+			}
+			if err := o.Run(c); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&o.from, "from", "", fmt.Sprintf("format of both documents, one of %v, derived from the file extensions if not set", documentFormats))
+	cmd.Flags().StringVar(&o.format, "format", formatText, fmt.Sprintf("output format, one of %v", diffFormats))
+	if err := cmd.RegisterFlagCompletionFunc("from", cobra.FixedCompletions(documentFormats, cobra.ShellCompDirectiveNoFileComp)); err != nil {
+		return nil, err
+	}
+	if err := cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(diffFormats, cobra.ShellCompDirectiveNoFileComp)); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
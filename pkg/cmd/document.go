@@ -0,0 +1,369 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"sigs.k8s.io/yaml"
+)
+
+const formatXML = "xml"
+
+var documentFormats = []string{formatJSON, formatYAML, formatXML}
+
+// documentFormatFromFile derives the document format from the file extension
+func documentFormatFromFile(file string) (string, error) {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		return formatJSON, nil
+	case ".yaml", ".yml":
+		return formatYAML, nil
+	case ".xml":
+		return formatXML, nil
+	}
+	return "", fmt.Errorf("can not derive the format of %q from its extension", file)
+}
+
+// decodeDocument decodes a config document into maps, slices and scalars.
+// Numbers are kept as json.Number to retain their exact representation.
+func decodeDocument(data []byte, format string) (any, error) {
+	switch format {
+	case formatJSON:
+		return decodeJSON(data)
+	case formatYAML:
+		j, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		return decodeJSON(j)
+	case formatXML:
+		return decodeXML(data)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+func decodeJSON(data []byte) (any, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// decodeXML decodes an XML document structurally. Elements with children
+// become maps, repeated elements become slices and text only elements
+// become strings. Element names are unescaped with decodeXMLName.
+// Attributes, including namespace declarations, are dropped.
+func decodeXML(data []byte) (any, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	root := map[string]any{}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			v, err := decodeXMLElement(d)
+			if err != nil {
+				return nil, err
+			}
+			addXMLMember(root, decodeXMLName(se.Name.Local), v)
+		}
+	}
+}
+
+// decodeXMLElement decodes the content of the element whose start tag was just read
+func decodeXMLElement(d *xml.Decoder) (any, error) {
+	members := map[string]any{}
+	text := strings.Builder{}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			v, err := decodeXMLElement(d)
+			if err != nil {
+				return nil, err
+			}
+			addXMLMember(members, decodeXMLName(t.Name.Local), v)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if len(members) > 0 {
+				return members, nil
+			}
+			return strings.TrimSpace(text.String()), nil
+		}
+	}
+}
+
+// addXMLMember adds v to m, turning repeated names into a slice
+func addXMLMember(m map[string]any, name string, v any) {
+	existing, exists := m[name]
+	if !exists {
+		m[name] = v
+		return
+	}
+	if s, ok := existing.([]any); ok {
+		m[name] = append(s, v)
+		return
+	}
+	m[name] = []any{existing, v}
+}
+
+var (
+	// xmlEscape matches the escape sequences of encodeXMLName
+	xmlEscape = regexp.MustCompile(`_x([0-9A-F]{4}|[0-9A-F]{8})_`)
+	// xmlEscapePrefix matches an escape sequence at the start of a string
+	xmlEscapePrefix = regexp.MustCompile(`^` + xmlEscape.String())
+)
+
+// encodeXMLName turns a member name into an XML element name. Characters that
+// are not allowed in an element name are written as _xHHHH_, their code point
+// in hex, as done by SQL/XML and .NET's XmlConvert. An underscore starting
+// such a sequence is escaped itself. The encoding depends on the name only,
+// so every list is written the same way: a list keyed by value has one child
+// element per entry named by its escaped key value, e.g. ethernet-1/1 becomes
+// <ethernet-1_x002F_1> and mgmt0 stays <mgmt0>.
+func encodeXMLName(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("empty names can not be represented in xml")
+	}
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' && xmlEscapePrefix.MatchString(name[i:]):
+			b.WriteString("_x005F_")
+		case isXMLNameRune(r, i == 0):
+			b.WriteRune(r)
+		case r > 0xFFFF:
+			fmt.Fprintf(&b, "_x%08X_", r)
+		default:
+			fmt.Fprintf(&b, "_x%04X_", r)
+		}
+	}
+	return b.String(), nil
+}
+
+// decodeXMLName reverses encodeXMLName
+func decodeXMLName(name string) string {
+	return xmlEscape.ReplaceAllStringFunc(name, func(seq string) string {
+		r, err := strconv.ParseUint(seq[2:len(seq)-1], 16, 32)
+		if err != nil {
+			return seq
+		}
+		return string(rune(r))
+	})
+}
+
+// isXMLNameRune reports whether r may appear in an XML element name without
+// prefix, at its start if first is set
+func isXMLNameRune(r rune, first bool) bool {
+	switch {
+	case unicode.IsLetter(r) || r == '_':
+		return true
+	case !first && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		return true
+	}
+	return false
+}
+
+// encodeDocument serializes a decoded config document in the given format
+func encodeDocument(v any, format string) ([]byte, error) {
+	switch format {
+	case formatJSON:
+		buf := &bytes.Buffer{}
+		e := json.NewEncoder(buf)
+		e.SetEscapeHTML(false)
+		e.SetIndent("", "  ")
+		if err := e.Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case formatYAML:
+		return yaml.Marshal(v)
+	case formatXML:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("xml output requires an object at the top level")
+		}
+		buf := &bytes.Buffer{}
+		if err := encodeXMLMembers(buf, m, ""); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// encodeXMLMembers writes the members of m as elements in name order
+func encodeXMLMembers(buf *bytes.Buffer, m map[string]any, indent string) error {
+	for _, name := range unionKeys(m, nil) {
+		element, err := encodeXMLName(name)
+		if err != nil {
+			return err
+		}
+		values, isList := m[name].([]any)
+		if !isList {
+			values = []any{m[name]}
+		}
+		for _, v := range values {
+			if err := encodeXMLElement(buf, element, v, indent); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encodeXMLElement writes v as element name
+func encodeXMLElement(buf *bytes.Buffer, name string, v any, indent string) error {
+	switch val := v.(type) {
+	case map[string]any:
+		fmt.Fprintf(buf, "%s<%s>\n", indent, name)
+		if err := encodeXMLMembers(buf, val, indent+"  "); err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "%s</%s>\n", indent, name)
+	case []any:
+		return fmt.Errorf("element %q: nested lists can not be represented in xml", decodeXMLName(name))
+	case nil:
+		fmt.Fprintf(buf, "%s<%s/>\n", indent, name)
+	default:
+		fmt.Fprintf(buf, "%s<%s>", indent, name)
+		if err := xml.EscapeText(buf, []byte(fmt.Sprint(val))); err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "</%s>\n", name)
+	}
+	return nil
+}
+
+// documentDiff holds the leaves that differ between two config documents
+type documentDiff struct {
+	Added   []documentDiffEntry `json:"added,omitempty"`
+	Removed []documentDiffEntry `json:"removed,omitempty"`
+	Changed []documentDiffEntry `json:"changed,omitempty"`
+}
+
+type documentDiffEntry struct {
+	Path string `json:"path"`
+	A    string `json:"a,omitempty"`
+	B    string `json:"b,omitempty"`
+}
+
+func (d *documentDiff) isEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// diffDocuments compares two decoded config documents leaf by leaf.
+// Maps are matched by name, which matches keyed lists written as maps by
+// their key values. Arrays are compared by position, as there is no schema
+// naming their keys.
+func diffDocuments(a, b any) *documentDiff {
+	d := &documentDiff{}
+	diffDocumentValues(a, b, "", d)
+	return d
+}
+
+func diffDocumentValues(a, b any, path string, d *documentDiff) {
+	switch va := a.(type) {
+	case map[string]any:
+		if vb, ok := b.(map[string]any); ok {
+			for _, name := range unionKeys(va, vb) {
+				ca, inA := va[name]
+				cb, inB := vb[name]
+				p := path + "/" + name
+				switch {
+				case !inA:
+					flattenDocument(cb, p, func(p, v string) { d.Added = append(d.Added, documentDiffEntry{Path: p, B: v}) })
+				case !inB:
+					flattenDocument(ca, p, func(p, v string) { d.Removed = append(d.Removed, documentDiffEntry{Path: p, A: v}) })
+				default:
+					diffDocumentValues(ca, cb, p, d)
+				}
+			}
+			return
+		}
+	case []any:
+		if vb, ok := b.([]any); ok {
+			for i := range max(len(va), len(vb)) {
+				p := fmt.Sprintf("%s[%d]", path, i)
+				switch {
+				case i >= len(va):
+					flattenDocument(vb[i], p, func(p, v string) { d.Added = append(d.Added, documentDiffEntry{Path: p, B: v}) })
+				case i >= len(vb):
+					flattenDocument(va[i], p, func(p, v string) { d.Removed = append(d.Removed, documentDiffEntry{Path: p, A: v}) })
+				default:
+					diffDocumentValues(va[i], vb[i], p, d)
+				}
+			}
+			return
+		}
+	default:
+		if !isDocumentContainer(b) {
+			if sa, sb := fmt.Sprint(a), fmt.Sprint(b); sa != sb {
+				d.Changed = append(d.Changed, documentDiffEntry{Path: path, A: sa, B: sb})
+			}
+			return
+		}
+	}
+	// the kind of the node changed, report all leaves on both sides
+	flattenDocument(a, path, func(p, v string) { d.Removed = append(d.Removed, documentDiffEntry{Path: p, A: v}) })
+	flattenDocument(b, path, func(p, v string) { d.Added = append(d.Added, documentDiffEntry{Path: p, B: v}) })
+}
+
+// flattenDocument calls fn for every leaf below v in document order
+func flattenDocument(v any, path string, fn func(path string, value string)) {
+	switch val := v.(type) {
+	case map[string]any:
+		for _, name := range unionKeys(val, nil) {
+			flattenDocument(val[name], path+"/"+name, fn)
+		}
+	case []any:
+		for i, e := range val {
+			flattenDocument(e, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	default:
+		fn(path, fmt.Sprint(val))
+	}
+}
+
+func isDocumentContainer(v any) bool {
+	switch v.(type) {
+	case map[string]any, []any:
+		return true
+	}
+	return false
+}
+
+// unionKeys returns the sorted names present in a or b
+func unionKeys(a, b map[string]any) []string {
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, exists := a[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestXMLKeyedListRoundTrip(t *testing.T) {
+	doc := map[string]any{
+		"interface": map[string]any{
+			"ethernet-1/1": map[string]any{
+				"admin-state": "enable",
+				"description": "uplink <a&b>",
+			},
+			"mgmt0": map[string]any{
+				"admin-state": "disable",
+			},
+		},
+		"network-instance": map[string]any{
+			"default": map[string]any{
+				"route": map[string]any{
+					"10.0.0.0/8": map[string]any{
+						"1": map[string]any{"metric": "10"},
+						"2": map[string]any{"metric": "20"},
+					},
+				},
+			},
+		},
+		"tunnel_x0041_": "literal escape",
+	}
+
+	out, err := encodeDocument(doc, formatXML)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	for _, want := range []string{
+		"<interface>\n  <ethernet-1_x002F_1>\n",
+		"  <mgmt0>\n",
+		"<network-instance>\n  <default>\n",
+		"<_x0031_0.0.0.0_x002F_8>\n        <_x0031_>\n",
+		"<tunnel_x005F_x0041_>",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	got, err := decodeDocument(out, formatXML)
+	if err != nil {
+		t.Fatalf("decode: %v\n%s", err, out)
+	}
+	if !reflect.DeepEqual(got, any(doc)) {
+		t.Errorf("round trip mismatch\n got: %v\nwant: %v", got, doc)
+	}
+}
+
+func TestXMLSingleEntryList(t *testing.T) {
+	// a list with one entry is written like any other list, whether or not
+	// its key value is a valid element name
+	tests := []struct {
+		doc  map[string]any
+		want string
+	}{
+		{
+			doc:  map[string]any{"network-instance": map[string]any{"default": map[string]any{"type": "default"}}},
+			want: "<network-instance>\n  <default>\n    <type>default</type>\n  </default>\n</network-instance>\n",
+		},
+		{
+			doc:  map[string]any{"interface": map[string]any{"mgmt0": map[string]any{"mtu": "1500"}}},
+			want: "<interface>\n  <mgmt0>\n    <mtu>1500</mtu>\n  </mgmt0>\n</interface>\n",
+		},
+		{
+			doc:  map[string]any{"interface": map[string]any{"ethernet-1/1": map[string]any{"mtu": "1500"}}},
+			want: "<interface>\n  <ethernet-1_x002F_1>\n    <mtu>1500</mtu>\n  </ethernet-1_x002F_1>\n</interface>\n",
+		},
+	}
+	for _, tt := range tests {
+		out, err := encodeDocument(tt.doc, formatXML)
+		if err != nil {
+			t.Fatalf("encode %v: %v", tt.doc, err)
+		}
+		if string(out) != tt.want {
+			t.Errorf("encode %v:\n%s\nwant:\n%s", tt.doc, out, tt.want)
+		}
+	}
+}
+
+func TestXMLInvalidElementName(t *testing.T) {
+	for _, doc := range []map[string]any{
+		{"": "x"},
+		{"interface": map[string]any{"": map[string]any{"admin-state": "enable"}}},
+	} {
+		if _, err := encodeDocument(doc, formatXML); err == nil {
+			t.Errorf("expected an error encoding %v", doc)
+		}
+	}
+}
+
+func TestXMLName(t *testing.T) {
+	tests := map[string]string{
+		"interface":       "interface",
+		"_x":              "_x",
+		"admin-state":     "admin-state",
+		"a.b":             "a.b",
+		"1":               "_x0031_",
+		"-a":              "_x002D_a",
+		"ethernet-1/1":    "ethernet-1_x002F_1",
+		"a:b":             "a_x003A_b",
+		"a b":             "a_x0020_b",
+		"_x0041_":         "_x005F_x0041_",
+		"emoji\U0001F600": "emoji_x0001F600_",
+	}
+	for name, want := range tests {
+		got, err := encodeXMLName(name)
+		if err != nil {
+			t.Errorf("encodeXMLName(%q): %v", name, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("encodeXMLName(%q) = %q, want %q", name, got, want)
+		}
+		if back := decodeXMLName(got); back != name {
+			t.Errorf("decodeXMLName(%q) = %q, want %q", got, back, name)
+		}
+	}
+}
+
+func TestDiffDocuments(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want documentDiff
+	}{
+		{
+			name: "identical",
+			a:    `{"system": {"name": "srl1"}}`,
+			b:    `{"system": {"name": "srl1"}}`,
+		},
+		{
+			name: "added, removed and changed leaves",
+			a:    `{"system": {"name": "srl1", "contact": "noc"}}`,
+			b:    `{"system": {"name": "srl2", "location": "lab"}}`,
+			want: documentDiff{
+				Added:   []documentDiffEntry{{Path: "/system/location", B: "lab"}},
+				Removed: []documentDiffEntry{{Path: "/system/contact", A: "noc"}},
+				Changed: []documentDiffEntry{{Path: "/system/name", A: "srl1", B: "srl2"}},
+			},
+		},
+		{
+			name: "reordered keyed list written as a map",
+			a:    `{"interface": {"ethernet-1/1": {"mtu": 1500}, "ethernet-1/2": {"mtu": 9000}}}`,
+			b:    `{"interface": {"ethernet-1/2": {"mtu": 9000}, "ethernet-1/1": {"mtu": 1500}}}`,
+		},
+		{
+			// arrays carry no key, so their entries are compared by position
+			name: "reordered list written as an array",
+			a:    `{"interface": [{"name": "ethernet-1/1"}, {"name": "ethernet-1/2"}]}`,
+			b:    `{"interface": [{"name": "ethernet-1/2"}, {"name": "ethernet-1/1"}]}`,
+			want: documentDiff{
+				Changed: []documentDiffEntry{
+					{Path: "/interface[0]/name", A: "ethernet-1/1", B: "ethernet-1/2"},
+					{Path: "/interface[1]/name", A: "ethernet-1/2", B: "ethernet-1/1"},
+				},
+			},
+		},
+		{
+			name: "entry appended to an array",
+			a:    `{"dns": ["1.1.1.1"]}`,
+			b:    `{"dns": ["1.1.1.1", "8.8.8.8"]}`,
+			want: documentDiff{
+				Added: []documentDiffEntry{{Path: "/dns[1]", B: "8.8.8.8"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := decodeDocument([]byte(tt.a), formatJSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := decodeDocument([]byte(tt.b), formatJSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := diffDocuments(a, b); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("diffDocuments() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
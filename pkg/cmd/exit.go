@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the plugin, see the README for their meaning
+const (
+	ExitOK          = 0
+	ExitError       = 1
+	ExitUsage       = 2
+	ExitNoMatch     = 3
+	ExitCheckFailed = 4
+	ExitCancelled   = 130
+)
+
+// ErrInterrupted is reported when the command is stopped by ctrl-c or SIGTERM
+var ErrInterrupted = &exitError{code: ExitCancelled, err: errors.New("interrupted")}
+
+// exitError carries the exit code the process should end with
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// usageError marks err as caused by invalid flags or arguments
+func usageError(err error) error {
+	return &exitError{code: ExitUsage, err: err}
+}
+
+// noMatchError reports that the command succeeded but found nothing
+func noMatchError(format string, a ...any) error {
+	return &exitError{code: ExitNoMatch, err: fmt.Errorf(format, a...)}
+}
+
+// checkFailedError reports that the command succeeded but the checked
+// condition does not hold, e.g. documents differ
+func checkFailedError(format string, a ...any) error {
+	return &exitError{code: ExitCheckFailed, err: fmt.Errorf(format, a...)}
+}
+
+// ExitCode returns the exit code for the error returned by a command
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+	return ExitError
+}
+
+// FlagErrorFunc makes flag parsing errors exit with ExitUsage, it is meant
+// to be set on the root command
+func FlagErrorFunc(_ *cobra.Command, err error) error {
+	return usageError(err)
+}
+
+// UsageArgs wraps the positional argument validation of a command so its
+// errors exit with ExitUsage
+func UsageArgs(args cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, a []string) error {
+		if err := args(cmd, a); err != nil {
+			return usageError(err)
+		}
+		return nil
+	}
+}
+
+// Error formats written by WriteError
+const (
+	ErrorFormatText = "text"
+	ErrorFormatJSON = "json"
+)
+
+var ErrorFormats = []string{ErrorFormatText, ErrorFormatJSON}
+
+// WriteError writes err as returned by a command, either as text in the
+// format cobra uses or as a json object carrying the exit code
+func WriteError(w io.Writer, err error, format string) {
+	if format != ErrorFormatJSON {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	out := struct {
+		Error struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}{}
+	out.Error.Code = ExitCode(err)
+	out.Error.Message = err.Error()
+	b, jerr := json.Marshal(out)
+	if jerr != nil {
+		fmt.Fprintln(w, "Error:", err)
+		return
+	}
+	fmt.Fprintln(w, string(b))
+}
+
+// ValidateErrorFormat checks the value of the --error-format flag
+func ValidateErrorFormat(format string) error {
+	if !slices.Contains(ErrorFormats, format) {
+		return usageError(fmt.Errorf("unknown error format %q, must be one of %v", format, ErrorFormats))
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// newTestRoot builds a root command wired like the plugin's main, with the
+// environment replaced by env
+func newTestRoot(t *testing.T, env map[string]string) *cobra.Command {
+	t.Helper()
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	root := &cobra.Command{Use: "sdcio", SilenceErrors: true}
+	root.SetFlagErrorFunc(FlagErrorFunc)
+	root.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		return PrepareFlags(cmd, "", func(name string) string { return env[name] })
+	}
+	for _, newCmd := range []func(genericiooptions.IOStreams) (*cobra.Command, error){
+		NewCmdBlame, NewCmdConvert, NewCmdDiff, NewCmdGet, NewCmdPush,
+	} {
+		cmd, err := newCmd(streams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root.AddCommand(cmd)
+	}
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	return root
+}
+
+func TestExitCodeUsageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+	}{
+		{name: "unknown flag", args: []string{"convert", "--unknown"}},
+		{name: "missing required flag of push", args: []string{"push", "--file", "config.json"}},
+		{name: "missing required flag of get", args: []string{"get"}},
+		{name: "missing required flag of blame", args: []string{"blame"}},
+		{name: "diff with one file", args: []string{"diff", "a.json"}},
+		{name: "diff format not derivable", args: []string{"diff", "a.txt", "b.json"}},
+		{name: "diff with three files", args: []string{"diff", "a.json", "b.json", "c.json"}},
+		{name: "invalid env default", args: []string{"blame", "--target", "dev"}, env: map[string]string{"SDCIO_RETRIES": "many"}},
+		{name: "invalid flag value", args: []string{"convert", "--from", "toml", "--to", "json"}},
+		{name: "input format not derivable", args: []string{"convert", "--input", "config.txt", "--to", "json"}},
+		{name: "output format not derivable", args: []string{"convert", "--from", "json", "--output", "config.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newTestRoot(t, tt.env)
+			root.SetArgs(tt.args)
+			err := root.Execute()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if code := ExitCode(err); code != ExitUsage {
+				t.Errorf("exit code %d for %q, want %d", code, err, ExitUsage)
+			}
+		})
+	}
+}
+
+func TestExitCodeRequiredFlagNotFromEnv(t *testing.T) {
+	root := newTestRoot(t, map[string]string{"SDCIO_TARGET_NAME": "srl1"})
+	root.SetArgs([]string{"push", "--file", filepath.Join(t.TempDir(), "missing.json"), "--dry-run"})
+	// --target-name is specific to push, so the env does not provide it
+	err := root.Execute()
+	if err == nil || !strings.Contains(err.Error(), "target-name") {
+		t.Fatalf("expected a missing --target-name error, got %v", err)
+	}
+	if code := ExitCode(err); code != ExitUsage {
+		t.Errorf("exit code %d, want %d", code, ExitUsage)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{errors.New("boom"), ExitError},
+		{usageError(errors.New("bad flag")), ExitUsage},
+		{noMatchError("no match"), ExitNoMatch},
+		{checkFailedError("differ"), ExitCheckFailed},
+		{errors.Join(errors.New("context"), checkFailedError("differ")), ExitCheckFailed},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWriteErrorInterrupted(t *testing.T) {
+	tests := map[string]string{
+		ErrorFormatText: "Error: interrupted\n",
+		ErrorFormatJSON: `{"error":{"code":130,"message":"interrupted"}}` + "\n",
+	}
+	for format, want := range tests {
+		buf := &bytes.Buffer{}
+		WriteError(buf, ErrInterrupted, format)
+		if buf.String() != want {
+			t.Errorf("%s: got %q, want %q", format, buf.String(), want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sdcio/kubectl-sdcio/pkg/client"
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+type GetOptions struct {
+	namespace string
+	target    string
+	path      string
+	format    string
+	MyOptions
+}
+
+// NewGetOptions provides an instance of GetOptions with default values
+func NewGetOptions(streams genericiooptions.IOStreams) *GetOptions {
+	return &GetOptions{
+		MyOptions: MyOptions{
+			configFlags: genericclioptions.NewConfigFlags(true),
+			IOStreams:   streams,
+		},
+	}
+}
+
+func (o *GetOptions) Complete(_ *cobra.Command, _ []string) error {
+	var err error
+	o.restConfig, err = o.configFlags.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.namespace, _, err = o.configFlags.ToRawKubeConfigLoader().Namespace()
+	return err
+}
+
+// Validate validates the options
+func (o *GetOptions) Validate() error {
+	if o.target == "" {
+		return fmt.Errorf("target not set")
+	}
+	if o.namespace == "" {
+		return fmt.Errorf("namespace not set")
+	}
+	if o.retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	if !slices.Contains(documentFormats, o.format) {
+		return fmt.Errorf("unknown format %q, must be one of %v", o.format, documentFormats)
+	}
+	return nil
+}
+
+func (o *GetOptions) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	cl, err := client.NewConfigClient(o.restConfig)
+	if err != nil {
+		return err
+	}
+	cl.SetRetries(o.retries)
+
+	tree, err := cl.GetBlameTree(ctx, o.namespace, o.target)
+	if err != nil {
+		return err
+	}
+
+	return o.writeDocument(tree)
+}
+
+// writeDocument writes the part of the tree selected by --path in the
+// format of --format
+func (o *GetOptions) writeDocument(tree *sdcpb.BlameTreeElement) error {
+	doc, err := client.BlameDocumentAt(tree, o.path)
+	if err != nil {
+		return err
+	}
+	b, err := encodeDocument(doc, o.format)
+	if err != nil {
+		return err
+	}
+	_, err = o.Out.Write(b)
+	return err
+}
+
+// NewCmdGet provides a cobra command wrapping GetOptions
+func NewCmdGet(streams genericiooptions.IOStreams) (*cobra.Command, error) {
+
+	o := NewGetOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "show the config applied to a target",
+		Long: `Show the config applied to a target as json, yaml or xml, as reported
+by the blame tree of the config-server. The schema is not known, so list
+entries are nested below the list by their key values. In xml, each key
+value becomes an element, with the characters not allowed in element names
+escaped as _xHHHH_, e.g. <interface><ethernet-1_x002F_1>.`,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return usageError(err)
+			}
+			if err := o.Run(c); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&o.target, "target", "", "target to get the applied config for")
+	cmd.Flags().StringVar(&o.path, "path", "", "only show the subtree at the given path, e.g. /interface[name=ethernet-1/1]")
+	cmd.Flags().StringVar(&o.format, "format", formatJSON, fmt.Sprintf("output format, one of %v", documentFormats))
+	cmd.Flags().IntVar(&o.retries, "retries", client.DefaultRetries, "number of times transient API errors are retried")
+	if err := cmd.MarkFlagRequired("target"); err != nil {
+		return nil, err
+	}
+	if err := cmd.RegisterFlagCompletionFunc("target", targetCompletionFunc(&o.MyOptions)); err != nil {
+		return nil, err
+	}
+	if err := cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(documentFormats, cobra.ShellCompDirectiveNoFileComp)); err != nil {
+		return nil, err
+	}
+	o.configFlags.AddFlags(cmd.Flags())
+	if err := registerKubeconfigCompletions(cmd, o.configFlags); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
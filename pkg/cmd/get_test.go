@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	sdcpb "github.com/sdcio/sdc-protos/sdcpb"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// newTestGetTree returns a blame tree with one interface list entry and a
+// container leaf
+func newTestGetTree() *sdcpb.BlameTreeElement {
+	leaf := func(name string, v *sdcpb.TypedValue) *sdcpb.BlameTreeElement {
+		return &sdcpb.BlameTreeElement{Name: name, Owner: "default.intent", Value: v}
+	}
+	return &sdcpb.BlameTreeElement{
+		Name: "root",
+		Childs: []*sdcpb.BlameTreeElement{
+			{Name: "interface", Childs: []*sdcpb.BlameTreeElement{
+				{Name: "ethernet-1/1", Childs: []*sdcpb.BlameTreeElement{
+					leaf("name", &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "ethernet-1/1"}}),
+					leaf("mtu", &sdcpb.TypedValue{Value: &sdcpb.TypedValue_UintVal{UintVal: 9000}}),
+				}},
+			}},
+			{Name: "system", Childs: []*sdcpb.BlameTreeElement{
+				leaf("name", &sdcpb.TypedValue{Value: &sdcpb.TypedValue_StringVal{StringVal: "srl1"}}),
+			}},
+		},
+	}
+}
+
+func TestGetWriteDocument(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		format string
+		want   string
+	}{
+		{
+			name:   "full tree as json",
+			format: formatJSON,
+			want: `{
+  "interface": {
+    "ethernet-1/1": {
+      "mtu": 9000,
+      "name": "ethernet-1/1"
+    }
+  },
+  "system": {
+    "name": "srl1"
+  }
+}
+`,
+		},
+		{
+			name:   "list entry as json",
+			path:   "/interface[name=ethernet-1/1]",
+			format: formatJSON,
+			want: `{
+  "interface": {
+    "ethernet-1/1": {
+      "mtu": 9000,
+      "name": "ethernet-1/1"
+    }
+  }
+}
+`,
+		},
+		{
+			name:   "list entry as xml",
+			path:   "/interface[name=ethernet-1/1]",
+			format: formatXML,
+			want: `<interface>
+  <ethernet-1_x002F_1>
+    <mtu>9000</mtu>
+    <name>ethernet-1/1</name>
+  </ethernet-1_x002F_1>
+</interface>
+`,
+		},
+		{
+			name:   "leaf of a list entry as xml",
+			path:   "/interface[name=ethernet-1/1]/mtu",
+			format: formatXML,
+			want:   "<mtu>9000</mtu>\n",
+		},
+		{
+			name:   "container as xml",
+			path:   "/system",
+			format: formatXML,
+			want:   "<system>\n  <name>srl1</name>\n</system>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streams, _, out, _ := genericiooptions.NewTestIOStreams()
+			o := NewGetOptions(streams)
+			o.path = tt.path
+			o.format = tt.format
+			if err := o.writeDocument(newTestGetTree()); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetWriteDocumentPathNotFound(t *testing.T) {
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	o := NewGetOptions(streams)
+	o.path = "/interface[name=ethernet-1/2]"
+	o.format = formatJSON
+	if err := o.writeDocument(newTestGetTree()); err == nil {
+		t.Error("expected an error for a missing list entry")
+	}
+}
+
+func TestGetTargetCompletion(t *testing.T) {
+	cmd, err := NewCmdGet(genericiooptions.IOStreams{In: &bytes.Buffer{}, Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cmd.GetFlagCompletionFunc("target"); !ok {
+		t.Error("--target has no completion")
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sdcio/kubectl-sdcio/pkg/client"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"sigs.k8s.io/yaml"
+)
+
+type NamespacesOptions struct {
+	format string
+	MyOptions
+}
+
+// NewNamespacesOptions provides an instance of NamespacesOptions with default values
+func NewNamespacesOptions(streams genericiooptions.IOStreams) *NamespacesOptions {
+	return &NamespacesOptions{
+		MyOptions: MyOptions{
+			configFlags: genericclioptions.NewConfigFlags(true),
+			IOStreams:   streams,
+		},
+	}
+}
+
+func (o *NamespacesOptions) Complete(_ *cobra.Command, _ []string) error {
+	var err error
+	o.restConfig, err = o.configFlags.ToRESTConfig()
+	return err
+}
+
+// Validate validates the options
+func (o *NamespacesOptions) Validate() error {
+	if o.retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	if !slices.Contains(statusFormats, o.format) {
+		return fmt.Errorf("unknown format %q, must be one of %v", o.format, statusFormats)
+	}
+	return nil
+}
+
+func (o *NamespacesOptions) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	cl, err := client.NewConfigClient(o.restConfig)
+	if err != nil {
+		return err
+	}
+	cl.SetRetries(o.retries)
+
+	namespaces, err := cl.ListNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch o.format {
+	case formatJSON:
+		b, err := json.MarshalIndent(namespaces, "", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(o.Out, string(b)); err != nil {
+			return err
+		}
+	case formatYAML:
+		b, err := yaml.Marshal(namespaces)
+		if err != nil {
+			return err
+		}
+		if _, err := o.Out.Write(b); err != nil {
+			return err
+		}
+	default:
+		if err := writeNamespaces(o, namespaces); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeNamespaces prints the namespaces as a table followed by the totals
+func writeNamespaces(o *NamespacesOptions, namespaces []client.NamespaceInfo) error {
+	total := client.NamespaceInfo{}
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tTARGETS\tCONFIGS")
+	for _, ns := range namespaces {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", ns.Namespace, ns.Targets, ns.Configs)
+		total.Targets += ns.Targets
+		total.Configs += ns.Configs
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(o.Out, "\n%d namespaces, %d targets, %d configs\n", len(namespaces), total.Targets, total.Configs)
+	return err
+}
+
+// NewCmdNamespaces provides a cobra command wrapping NamespacesOptions
+func NewCmdNamespaces(streams genericiooptions.IOStreams) (*cobra.Command, error) {
+
+	o := NewNamespacesOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "namespaces",
+		Short:        "list the namespaces holding targets or configs",
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return usageError(err)
+			}
+			if err := o.Run(c); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&o.format, "format", formatTable, fmt.Sprintf("output format, one of %v", statusFormats))
+	cmd.Flags().IntVar(&o.retries, "retries", client.DefaultRetries, "number of times transient API errors are retried")
+	if err := cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(statusFormats, cobra.ShellCompDirectiveNoFileComp)); err != nil {
+		return nil, err
+	}
+	o.configFlags.AddFlags(cmd.Flags())
+	if err := registerKubeconfigCompletions(cmd, o.configFlags); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
@@ -9,5 +9,6 @@ import (
 type MyOptions struct {
 	restConfig  *rest.Config
 	configFlags *genericclioptions.ConfigFlags
+	retries     int
 	genericiooptions.IOStreams
 }
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"strings"
+)
+
+// gzipExtension marks output files that are written gzip compressed
+const gzipExtension = ".gz"
+
+// writeOutputFile writes data to path, gzip compressed if compress is set or
+// path ends with .gz
+func writeOutputFile(path string, data []byte, compress bool) error {
+	if !compress && !strings.HasSuffix(path, gzipExtension) {
+		return os.WriteFile(path, data, 0644)
+	}
+
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sdcio/config-server/apis/config"
+	configv1alpha1 "github.com/sdcio/config-server/apis/config/v1alpha1"
+	"github.com/sdcio/kubectl-sdcio/pkg/client"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+type PushOptions struct {
+	namespace       string
+	targetName      string
+	targetNamespace string
+	name            string
+	path            string
+	file            string
+	from            string
+	priority        int64
+	deletionPolicy  string
+	revertive       bool
+	revertiveSet    bool
+	labels          []string
+	dryRun          bool
+	MyOptions
+}
+
+var deletionPolicies = []string{string(configv1alpha1.DeletionDelete), string(configv1alpha1.DeletionOrphan)}
+
+// NewPushOptions provides an instance of PushOptions with default values
+func NewPushOptions(streams genericiooptions.IOStreams) *PushOptions {
+	return &PushOptions{
+		MyOptions: MyOptions{
+			configFlags: genericclioptions.NewConfigFlags(true),
+			IOStreams:   streams,
+		},
+	}
+}
+
+func (o *PushOptions) Complete(cmd *cobra.Command, _ []string) error {
+	var err error
+	if cmd != nil {
+		o.revertiveSet = cmd.Flags().Changed("revertive")
+	}
+	if o.from == "" && o.file != "" {
+		if o.from, err = documentFormatFromFile(o.file); err != nil {
+			return usageError(fmt.Errorf("%w, use --from", err))
+		}
+	}
+	if o.name == "" {
+		o.name = o.targetName
+	}
+
+	clientConfig := o.configFlags.ToRawKubeConfigLoader()
+	o.namespace, _, err = clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	if o.targetNamespace == "" {
+		o.targetNamespace = o.namespace
+	}
+	// a dry run only prints the manifest and does not need a cluster
+	if o.dryRun {
+		return nil
+	}
+	o.restConfig, err = o.configFlags.ToRESTConfig()
+	return err
+}
+
+// Validate validates the options
+func (o *PushOptions) Validate() error {
+	if o.targetName == "" {
+		return fmt.Errorf("target-name not set")
+	}
+	if o.namespace == "" {
+		return fmt.Errorf("namespace not set")
+	}
+	if o.file == "" {
+		return fmt.Errorf("file not set")
+	}
+	if !slices.Contains(documentFormats, o.from) {
+		return fmt.Errorf("unknown format %q, must be one of %v", o.from, documentFormats)
+	}
+	if o.deletionPolicy != "" && !slices.Contains(deletionPolicies, o.deletionPolicy) {
+		return fmt.Errorf("unknown deletion policy %q, must be one of %v", o.deletionPolicy, deletionPolicies)
+	}
+	if _, err := o.labelSet(); err != nil {
+		return err
+	}
+	if o.retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	return nil
+}
+
+// labelSet returns the labels of the Config, the --label flags
+// complemented by the target labels
+func (o *PushOptions) labelSet() (map[string]string, error) {
+	labels := map[string]string{}
+	for _, l := range o.labels {
+		key, value, found := strings.Cut(l, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid label %q, must be key=value", l)
+		}
+		labels[key] = value
+	}
+	labels[config.TargetNameKey] = o.targetName
+	labels[config.TargetNamespaceKey] = o.targetNamespace
+	return labels, nil
+}
+
+// config builds the Config resource carrying the document as intent
+func (o *PushOptions) config() (*configv1alpha1.Config, error) {
+	data, err := os.ReadFile(o.file)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeDocument(data, o.from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", o.file, err)
+	}
+	value, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := o.labelSet()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &configv1alpha1.Config{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: configv1alpha1.SchemeGroupVersion.Identifier(),
+			Kind:       configv1alpha1.ConfigKind,
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: o.namespace,
+			Name:      o.name,
+			Labels:    labels,
+		},
+		Spec: configv1alpha1.ConfigSpec{
+			Priority: o.priority,
+			Config: []configv1alpha1.ConfigBlob{
+				{Path: o.path, Value: runtime.RawExtension{Raw: value}},
+			},
+		},
+	}
+	if o.deletionPolicy != "" {
+		cfg.Spec.Lifecycle = &configv1alpha1.Lifecycle{DeletionPolicy: configv1alpha1.DeletionPolicy(o.deletionPolicy)}
+	}
+	if o.revertiveSet {
+		cfg.Spec.Revertive = ptr.To(o.revertive)
+	}
+	return cfg, nil
+}
+
+func (o *PushOptions) Run(cmd *cobra.Command) error {
+	cfg, err := o.config()
+	if err != nil {
+		return err
+	}
+
+	if o.dryRun {
+		b, err := yaml.Marshal(cfg)
+		if err != nil {
+			return err
+		}
+		_, err = o.Out.Write(b)
+		return err
+	}
+
+	cl, err := client.NewConfigClient(o.restConfig)
+	if err != nil {
+		return err
+	}
+	cl.SetRetries(o.retries)
+
+	applied, err := cl.ApplyConfig(cmd.Context(), cfg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(o.Out, "config %s/%s applied\n", applied.Namespace, applied.Name)
+	return err
+}
+
+// NewCmdPush provides a cobra command wrapping PushOptions
+func NewCmdPush(streams genericiooptions.IOStreams) (*cobra.Command, error) {
+
+	o := NewPushOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "apply a config document as a Config resource of a target",
+		Long: `Apply a json, yaml or xml config document as the intent of a Config
+resource for the given target. The document is applied at --path, the
+Config is created or updated with server-side apply.`,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return usageError(err)
+			}
+			if err := o.Run(c); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&o.targetName, "target-name", "", "target the config is applied to")
+	cmd.Flags().StringVar(&o.targetNamespace, "target-namespace", "", "namespace of the target, defaults to the namespace of the Config")
+	cmd.Flags().StringVar(&o.name, "name", "", "name of the Config resource, defaults to the target name")
+	cmd.Flags().StringVar(&o.path, "path", "/", "path the document is applied at")
+	cmd.Flags().StringVar(&o.file, "file", "", "file to read the config document from")
+	cmd.Flags().StringVar(&o.from, "from", "", fmt.Sprintf("format of the document, one of %v, derived from the --file extension if not set", documentFormats))
+	cmd.Flags().Int64Var(&o.priority, "priority", 10, "priority of the Config resource")
+	cmd.Flags().StringVar(&o.deletionPolicy, "deletion-policy", "", fmt.Sprintf("what happens to the device config when the Config is deleted, one of %v, the config-server default if not set", deletionPolicies))
+	cmd.Flags().BoolVar(&o.revertive, "revertive", false, "whether deviations from the Config are reverted, the config-server default if not set")
+	cmd.Flags().StringArrayVar(&o.labels, "label", nil, "label of the Config as key=value, can be repeated")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "print the Config manifest instead of applying it")
+	cmd.Flags().IntVar(&o.retries, "retries", client.DefaultRetries, "number of times transient API errors are retried")
+	if err := cmd.MarkFlagRequired("target-name"); err != nil {
+		return nil, err
+	}
+	if err := cmd.MarkFlagRequired("file"); err != nil {
+		return nil, err
+	}
+	if err := cmd.RegisterFlagCompletionFunc("deletion-policy", cobra.FixedCompletions(deletionPolicies, cobra.ShellCompDirectiveNoFileComp)); err != nil {
+		return nil, err
+	}
+	if err := cmd.RegisterFlagCompletionFunc("from", cobra.FixedCompletions(documentFormats, cobra.ShellCompDirectiveNoFileComp)); err != nil {
+		return nil, err
+	}
+	o.configFlags.AddFlags(cmd.Flags())
+	if err := registerKubeconfigCompletions(cmd, o.configFlags); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sdcio/config-server/apis/config"
+	configv1alpha1 "github.com/sdcio/config-server/apis/config/v1alpha1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+// pushDryRun runs push --dry-run with the given arguments on a document file
+// and returns the Config it prints
+func pushDryRun(t *testing.T, args ...string) (*configv1alpha1.Config, error) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte("system:\n  name: srl1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	streams, _, out, _ := genericiooptions.NewTestIOStreams()
+	cmd, err := NewCmdPush(streams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd.SetArgs(append([]string{"--file", file, "--dry-run", "--namespace", "network"}, args...))
+	cmd.SetOut(&strings.Builder{})
+	cmd.SetErr(&strings.Builder{})
+	if err := cmd.Execute(); err != nil {
+		return nil, err
+	}
+	cfg := &configv1alpha1.Config{}
+	if err := yaml.Unmarshal(out.Bytes(), cfg); err != nil {
+		t.Fatalf("dry run printed an invalid Config: %v\n%s", err, out)
+	}
+	return cfg, nil
+}
+
+func TestPushConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		wantName      string
+		wantPriority  int64
+		wantRevertive *bool
+		wantLabels    map[string]string
+		wantLifecycle *configv1alpha1.Lifecycle
+	}{
+		{
+			name:         "defaults",
+			args:         []string{"--target-name", "srl1"},
+			wantName:     "srl1",
+			wantPriority: 10,
+			wantLabels:   map[string]string{config.TargetNameKey: "srl1", config.TargetNamespaceKey: "network"},
+		},
+		{
+			name:         "name and priority",
+			args:         []string{"--target-name", "srl1", "--name", "system", "--priority", "20"},
+			wantName:     "system",
+			wantPriority: 20,
+			wantLabels:   map[string]string{config.TargetNameKey: "srl1", config.TargetNamespaceKey: "network"},
+		},
+		{
+			name:          "revertive set to false",
+			args:          []string{"--target-name", "srl1", "--revertive=false"},
+			wantName:      "srl1",
+			wantPriority:  10,
+			wantRevertive: ptr.To(false),
+			wantLabels:    map[string]string{config.TargetNameKey: "srl1", config.TargetNamespaceKey: "network"},
+		},
+		{
+			name:         "repeated labels and target namespace",
+			args:         []string{"--target-name", "srl1", "--target-namespace", "lab", "--label", "team=core", "--label", "site=ams"},
+			wantName:     "srl1",
+			wantPriority: 10,
+			wantLabels: map[string]string{
+				"team":                    "core",
+				"site":                    "ams",
+				config.TargetNameKey:      "srl1",
+				config.TargetNamespaceKey: "lab",
+			},
+		},
+		{
+			name:          "deletion policy",
+			args:          []string{"--target-name", "srl1", "--deletion-policy", "orphan"},
+			wantName:      "srl1",
+			wantPriority:  10,
+			wantLabels:    map[string]string{config.TargetNameKey: "srl1", config.TargetNamespaceKey: "network"},
+			wantLifecycle: &configv1alpha1.Lifecycle{DeletionPolicy: configv1alpha1.DeletionOrphan},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := pushDryRun(t, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Kind != configv1alpha1.ConfigKind || cfg.Namespace != "network" {
+				t.Errorf("got %s %s/%s, want a Config in namespace network", cfg.Kind, cfg.Namespace, cfg.Name)
+			}
+			if cfg.Name != tt.wantName {
+				t.Errorf("name %q, want %q", cfg.Name, tt.wantName)
+			}
+			if cfg.Spec.Priority != tt.wantPriority {
+				t.Errorf("priority %d, want %d", cfg.Spec.Priority, tt.wantPriority)
+			}
+			if !reflect.DeepEqual(cfg.Spec.Revertive, tt.wantRevertive) {
+				t.Errorf("revertive %v, want %v", cfg.Spec.Revertive, tt.wantRevertive)
+			}
+			if !reflect.DeepEqual(cfg.Labels, tt.wantLabels) {
+				t.Errorf("labels %v, want %v", cfg.Labels, tt.wantLabels)
+			}
+			if !reflect.DeepEqual(cfg.Spec.Lifecycle, tt.wantLifecycle) {
+				t.Errorf("lifecycle %v, want %v", cfg.Spec.Lifecycle, tt.wantLifecycle)
+			}
+			if len(cfg.Spec.Config) != 1 || cfg.Spec.Config[0].Path != "/" ||
+				string(cfg.Spec.Config[0].Value.Raw) != `{"system":{"name":"srl1"}}` {
+				t.Errorf("config blobs %v, want the document at /", cfg.Spec.Config)
+			}
+		})
+	}
+}
+
+func TestPushInvalidLabel(t *testing.T) {
+	for _, label := range []string{"team", "=core"} {
+		_, err := pushDryRun(t, "--target-name", "srl1", "--label", label)
+		if err == nil {
+			t.Errorf("expected an error for label %q", label)
+			continue
+		}
+		if code := ExitCode(err); code != ExitUsage {
+			t.Errorf("exit code %d for label %q, want %d", code, label, ExitUsage)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sdcio/kubectl-sdcio/pkg/client"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"sigs.k8s.io/yaml"
+)
+
+const formatTable = "table"
+
+var statusFormats = []string{formatTable, formatJSON, formatYAML}
+
+type StatusOptions struct {
+	format string
+	MyOptions
+}
+
+// NewStatusOptions provides an instance of StatusOptions with default values
+func NewStatusOptions(streams genericiooptions.IOStreams) *StatusOptions {
+	return &StatusOptions{
+		MyOptions: MyOptions{
+			configFlags: genericclioptions.NewConfigFlags(true),
+			IOStreams:   streams,
+		},
+	}
+}
+
+func (o *StatusOptions) Complete(_ *cobra.Command, _ []string) error {
+	var err error
+	o.restConfig, err = o.configFlags.ToRESTConfig()
+	return err
+}
+
+// Validate validates the options
+func (o *StatusOptions) Validate() error {
+	if o.retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	if !slices.Contains(statusFormats, o.format) {
+		return fmt.Errorf("unknown format %q, must be one of %v", o.format, statusFormats)
+	}
+	return nil
+}
+
+func (o *StatusOptions) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	cl, err := client.NewConfigClient(o.restConfig)
+	if err != nil {
+		return err
+	}
+	cl.SetRetries(o.retries)
+
+	info, err := cl.ServerInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch o.format {
+	case formatJSON:
+		b, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(o.Out, string(b)); err != nil {
+			return err
+		}
+	case formatYAML:
+		b, err := yaml.Marshal(info)
+		if err != nil {
+			return err
+		}
+		if _, err := o.Out.Write(b); err != nil {
+			return err
+		}
+	default:
+		if err := writeServerInfo(o, cmd.Root().Version, info); err != nil {
+			return err
+		}
+	}
+
+	if !info.Compatible() {
+		return checkFailedError("config-server does not serve all resources required by the plugin")
+	}
+	return nil
+}
+
+// writeServerInfo prints the server info as a human readable table
+func writeServerInfo(o *StatusOptions, pluginVersion string, info *client.ServerInfo) error {
+	fmt.Fprintf(o.Out, "Plugin version:     %s\n", pluginVersion)
+	fmt.Fprintf(o.Out, "Kubernetes version: %s\n", info.KubernetesVersion)
+
+	groups := make([]string, 0, len(info.Groups))
+	for g := range info.Groups {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+	for _, g := range groups {
+		fmt.Fprintf(o.Out, "API group %s: %s\n", g, strings.Join(info.Groups[g], ", "))
+	}
+	fmt.Fprintln(o.Out)
+
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "RESOURCE\tREGISTERED")
+	for _, r := range info.Resources {
+		fmt.Fprintf(tw, "%s\t%t\n", r.Resource, r.Registered)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, w := range info.Warnings {
+		fmt.Fprintf(o.ErrOut, "warning: %s\n", w)
+	}
+	return nil
+}
+
+// NewCmdStatus provides a cobra command wrapping StatusOptions
+func NewCmdStatus(streams genericiooptions.IOStreams) (*cobra.Command, error) {
+
+	o := NewStatusOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "status",
+		Short:        "check the config-server API the plugin talks to",
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return usageError(err)
+			}
+			if err := o.Run(c); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&o.format, "format", formatTable, fmt.Sprintf("output format, one of %v", statusFormats))
+	cmd.Flags().IntVar(&o.retries, "retries", client.DefaultRetries, "number of times transient API errors are retried")
+	if err := cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(statusFormats, cobra.ShellCompDirectiveNoFileComp)); err != nil {
+		return nil, err
+	}
+	o.configFlags.AddFlags(cmd.Flags())
+	if err := registerKubeconfigCompletions(cmd, o.configFlags); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
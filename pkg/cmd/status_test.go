@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/sdcio/kubectl-sdcio/pkg/client"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+func TestStatusRetries(t *testing.T) {
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	cmd, err := NewCmdStatus(streams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := cmd.Flags().Lookup("retries")
+	if f == nil {
+		t.Fatal("status has no --retries flag")
+	}
+	if f.DefValue != strconv.Itoa(client.DefaultRetries) {
+		t.Errorf("--retries defaults to %s, want %d", f.DefValue, client.DefaultRetries)
+	}
+
+	tests := []struct {
+		name    string
+		retries int
+		wantErr bool
+	}{
+		{name: "no retries", retries: 0},
+		{name: "default", retries: client.DefaultRetries},
+		{name: "negative", retries: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := StatusOptions{format: formatTable, MyOptions: MyOptions{retries: tt.retries}}
+			if err := o.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
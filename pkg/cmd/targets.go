@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sdcio/kubectl-sdcio/pkg/client"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"sigs.k8s.io/yaml"
+)
+
+type TargetsOptions struct {
+	namespace     string
+	allNamespaces bool
+	selector      string
+	format        string
+	MyOptions
+}
+
+// NewTargetsOptions provides an instance of TargetsOptions with default values
+func NewTargetsOptions(streams genericiooptions.IOStreams) *TargetsOptions {
+	return &TargetsOptions{
+		MyOptions: MyOptions{
+			configFlags: genericclioptions.NewConfigFlags(true),
+			IOStreams:   streams,
+		},
+	}
+}
+
+func (o *TargetsOptions) Complete(_ *cobra.Command, _ []string) error {
+	var err error
+	o.restConfig, err = o.configFlags.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.allNamespaces {
+		o.namespace = v1.NamespaceAll
+		return nil
+	}
+	o.namespace, _, err = o.configFlags.ToRawKubeConfigLoader().Namespace()
+	return err
+}
+
+// Validate validates the options
+func (o *TargetsOptions) Validate() error {
+	if o.namespace == "" && !o.allNamespaces {
+		return fmt.Errorf("namespace not set")
+	}
+	if o.retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	if !slices.Contains(statusFormats, o.format) {
+		return fmt.Errorf("unknown format %q, must be one of %v", o.format, statusFormats)
+	}
+	return nil
+}
+
+func (o *TargetsOptions) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	cl, err := client.NewConfigClient(o.restConfig)
+	if err != nil {
+		return err
+	}
+	cl.SetRetries(o.retries)
+
+	targets, err := cl.GetTargetsWithSelector(ctx, o.namespace, o.selector)
+	if err != nil {
+		return err
+	}
+
+	switch o.format {
+	case formatJSON:
+		b, err := json.MarshalIndent(targets, "", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(o.Out, string(b)); err != nil {
+			return err
+		}
+	case formatYAML:
+		b, err := yaml.Marshal(targets)
+		if err != nil {
+			return err
+		}
+		if _, err := o.Out.Write(b); err != nil {
+			return err
+		}
+	default:
+		if err := writeTargets(o, targets); err != nil {
+			return err
+		}
+	}
+	if len(targets) == 0 {
+		return noMatchError("no targets found")
+	}
+	return nil
+}
+
+// writeTargets prints the targets as a table
+func writeTargets(o *TargetsOptions, targets []client.TargetInfo) error {
+	tw := tabwriter.NewWriter(o.Out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tNAMESPACE\tCONNECTED\tREADY\tPROVIDER\tADDRESS")
+	for _, t := range targets {
+		fmt.Fprintf(tw, "%s\t%s\t%t\t%t\t%s\t%s\n", t.Name, t.Namespace, t.Connected, t.Ready, t.Provider, t.Address)
+	}
+	return tw.Flush()
+}
+
+// NewCmdTargets provides a cobra command wrapping TargetsOptions
+func NewCmdTargets(streams genericiooptions.IOStreams) (*cobra.Command, error) {
+
+	o := NewTargetsOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:          "targets",
+		Short:        "list the targets and their connection status",
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return usageError(err)
+			}
+			if err := o.Run(c); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.allNamespaces, "all-namespaces", "A", false, "list the targets of all namespaces")
+	cmd.Flags().StringVarP(&o.selector, "selector", "l", "", "label selector to filter the targets, e.g. vendor=nokia")
+	cmd.Flags().StringVarP(&o.format, "output", "o", formatTable, fmt.Sprintf("output format, one of %v", statusFormats))
+	cmd.Flags().IntVar(&o.retries, "retries", client.DefaultRetries, "number of times transient API errors are retried")
+	if err := cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(statusFormats, cobra.ShellCompDirectiveNoFileComp)); err != nil {
+		return nil, err
+	}
+	o.configFlags.AddFlags(cmd.Flags())
+	if err := registerKubeconfigCompletions(cmd, o.configFlags); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
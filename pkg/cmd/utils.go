@@ -1,10 +1,13 @@
 package cmd
 
 import (
-	"context"
+	"maps"
+	"slices"
 
 	"github.com/sdcio/kubectl-sdcio/pkg/client"
 	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 func compError(err error) ([]string, cobra.ShellCompDirective) {
@@ -14,18 +17,24 @@ func compError(err error) ([]string, cobra.ShellCompDirective) {
 
 // targetCompletionFunc is a completion function that completes target
 // that match the toComplete prefix.
-func targetCompletionFunc(o *BlameOptions) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		if err := o.Complete(nil, nil); err != nil {
+func targetCompletionFunc(o *MyOptions) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		restConfig, err := o.configFlags.ToRESTConfig()
+		if err != nil {
+			return compError(err)
+		}
+		namespace, _, err := o.configFlags.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
 			return compError(err)
 		}
 
-		cl, err := client.NewConfigClient(o.restConfig)
+		cl, err := client.NewConfigClient(restConfig)
 		if err != nil {
 			return compError(err)
 		}
+		cl.SetRetries(o.retries)
 
-		comps, err := cl.GetTargetNames(context.Background(), o.namespace)
+		comps, err := cl.GetTargetNames(cmd.Context(), namespace)
 		if err != nil {
 			return compError(err)
 		}
@@ -33,3 +42,26 @@ func targetCompletionFunc(o *BlameOptions) func(cmd *cobra.Command, args []strin
 		return comps, cobra.ShellCompDirectiveNoFileComp
 	}
 }
+
+// registerKubeconfigCompletions registers completion of the --context,
+// --cluster and --user flags from the entries of the kubeconfig
+func registerKubeconfigCompletions(cmd *cobra.Command, configFlags *genericclioptions.ConfigFlags) error {
+	completions := map[string]func(cfg clientcmdapi.Config) []string{
+		"context": func(cfg clientcmdapi.Config) []string { return slices.Sorted(maps.Keys(cfg.Contexts)) },
+		"cluster": func(cfg clientcmdapi.Config) []string { return slices.Sorted(maps.Keys(cfg.Clusters)) },
+		"user":    func(cfg clientcmdapi.Config) []string { return slices.Sorted(maps.Keys(cfg.AuthInfos)) },
+	}
+	for flag, names := range completions {
+		err := cmd.RegisterFlagCompletionFunc(flag, func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			cfg, err := configFlags.ToRawKubeConfigLoader().RawConfig()
+			if err != nil {
+				return compError(err)
+			}
+			return names(cfg), cobra.ShellCompDirectiveNoFileComp
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
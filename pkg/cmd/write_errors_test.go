@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sdcio/kubectl-sdcio/pkg/client"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+var errBrokenPipe = errors.New("broken pipe")
+
+// failingWriter fails every write, like a closed pipe
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errBrokenPipe }
+
+func TestTableWriteErrors(t *testing.T) {
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	streams.Out = failingWriter{}
+
+	namespaces := NewNamespacesOptions(streams)
+	if err := writeNamespaces(namespaces, []client.NamespaceInfo{{Namespace: "default", Targets: 1}}); !errors.Is(err, errBrokenPipe) {
+		t.Errorf("writeNamespaces returned %v, want %v", err, errBrokenPipe)
+	}
+	targets := NewTargetsOptions(streams)
+	if err := writeTargets(targets, []client.TargetInfo{{Namespace: "default", Name: "srl1"}}); !errors.Is(err, errBrokenPipe) {
+		t.Errorf("writeTargets returned %v, want %v", err, errBrokenPipe)
+	}
+	status := NewStatusOptions(streams)
+	if err := writeServerInfo(status, "v0.0.0", &client.ServerInfo{}); !errors.Is(err, errBrokenPipe) {
+		t.Errorf("writeServerInfo returned %v, want %v", err, errBrokenPipe)
+	}
+}
+
+func TestDiffWriteErrors(t *testing.T) {
+	diff := &documentDiff{Changed: []documentDiffEntry{{Path: "/system/name", A: "a", B: "b"}}}
+	if err := writeDiffText(failingWriter{}, colorizer{}, []string{"a.json", "b.json"}, diff); !errors.Is(err, errBrokenPipe) {
+		t.Errorf("writeDiffText returned %v, want %v", err, errBrokenPipe)
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import "strings"
+
+// SplitPath splits an xpath into its segments on '/', ignoring slashes
+// inside [...] key predicates, e.g. "/route[prefix=10.0.0.0/8]/metric"
+// becomes ["route[prefix=10.0.0.0/8]", "metric"]. Brackets escaped with a
+// backslash do not open or close a predicate. Empty segments are dropped.
+func SplitPath(path string) []string {
+	result := []string{}
+	sb := strings.Builder{}
+	inKey := false
+	escaped := false
+	for _, r := range path {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '[':
+			inKey = true
+		case r == ']':
+			inKey = false
+		case r == '/' && !inKey:
+			if sb.Len() > 0 {
+				result = append(result, sb.String())
+				sb.Reset()
+			}
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	if sb.Len() > 0 {
+		result = append(result, sb.String())
+	}
+	return result
+}
+
+// LocalName strips the module or prefix qualification of a path segment,
+// e.g. "oc-if:interface" becomes "interface". Colons inside [...] key
+// predicates, e.g. of IPv6 addresses, are kept.
+func LocalName(segment string) string {
+	name := segment
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return segment[i+1:]
+	}
+	return segment
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"", []string{}},
+		{"/", []string{}},
+		{"/interface", []string{"interface"}},
+		{"interface/mtu", []string{"interface", "mtu"}},
+		{"//interface//mtu/", []string{"interface", "mtu"}},
+		{"/interface[name=ethernet-1/1]/mtu", []string{"interface[name=ethernet-1/1]", "mtu"}},
+		{
+			"/network-instance[name=default]/route[prefix=10.0.0.0/8][next-hop=a/b]/metric",
+			[]string{"network-instance[name=default]", "route[prefix=10.0.0.0/8][next-hop=a/b]", "metric"},
+		},
+		{`/a[name=x\]/y]/b`, []string{`a[name=x\]/y]`, "b"}},
+	}
+	for _, tt := range tests {
+		if got := SplitPath(tt.path); !slices.Equal(got, tt.want) {
+			t.Errorf("SplitPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLocalName(t *testing.T) {
+	tests := map[string]string{
+		"interface":                          "interface",
+		"oc-if:interface":                    "interface",
+		"oc-if:interface[name=ethernet-1/1]": "interface[name=ethernet-1/1]",
+		"neighbor[address=2001:db8::1]":      "neighbor[address=2001:db8::1]",
+		"bgp:neighbor[address=2001:db8::1]":  "neighbor[address=2001:db8::1]",
+		"":                                   "",
+	}
+	for segment, want := range tests {
+		if got := LocalName(segment); got != want {
+			t.Errorf("LocalName(%q) = %q, want %q", segment, got, want)
+		}
+	}
+}
+
+func TestLocalNameOfMixedSegments(t *testing.T) {
+	path := "/oc-if:interfaces/interface[name=ethernet-1/1]/oc-ip:ipv6/address[ip=2001:db8::1]/config"
+	want := []string{"interfaces", "interface[name=ethernet-1/1]", "ipv6", "address[ip=2001:db8::1]", "config"}
+
+	got := []string{}
+	for _, segment := range SplitPath(path) {
+		got = append(got, LocalName(segment))
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("local names of %q = %q, want %q", path, got, want)
+	}
+}